@@ -0,0 +1,97 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type rolesKey struct{}
+
+// callerRoles holds the caller roles resolved by the server interceptors,
+// kept up to date by the streams re-authorization
+type callerRoles interface {
+	callerRoles() []Role
+}
+
+type staticRoles []Role
+
+func (r staticRoles) callerRoles() []Role {
+	return r
+}
+
+// RolesFromContext returns the caller roles resolved by the server interceptors
+func RolesFromContext(ctx context.Context) ([]Role, bool) {
+	v, ok := ctx.Value(rolesKey{}).(callerRoles)
+	if !ok {
+		return nil, false
+	}
+	return v.callerRoles(), true
+}
+
+// Can reports whether the caller roles resolved by the server interceptors are allowed the permission
+func Can(ctx context.Context, perm Permission) bool {
+	return Require(ctx, perm) == nil
+}
+
+// Require returns a PermissionDenied error if the caller roles resolved by the server interceptors
+// are not allowed the permission, an Unauthenticated error if there is no caller roles,
+// or an Internal error if the context does not come from the server interceptors.
+// The PermissionDenied and Unauthenticated errors are converted by the ErrorMapper.
+func Require(ctx context.Context, perm Permission) error {
+	v, _ := FromContext(ctx)
+	r, ok := v.(*rbac)
+	if !ok {
+		return status.Error(codes.Internal, "grpc rbac: missing rbac engine in context")
+	}
+	roles, ok := RolesFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Internal, "grpc rbac: missing roles in context")
+	}
+	if r.isPublic(perm.ID()) {
+		return nil
+	}
+	if len(roles) == 0 {
+		return r.mapError(ErrUnauthenticated)
+	}
+	for _, v := range roles {
+		if r.rbac.IsGranted(v.ID(), perm, nil) {
+			return nil
+		}
+	}
+	return r.mapError(r.methodDenied(roles, perm))
+}
+
+func (r *rbac) Authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	ctx = context.WithValue(ctx, key{}, r)
+	roles, err := r.match(ctx, fullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, rolesKey{}, staticRoles(roles)), nil
+}
+
+func (r *rbac) CheckFields(ctx context.Context, msg interface{}) error {
+	roles, _ := RolesFromContext(ctx)
+	return r.check(roles, msg)
+}
+
+func (r *rbac) MaskFields(ctx context.Context, msg interface{}) interface{} {
+	roles, _ := RolesFromContext(ctx)
+	return r.mask(roles, msg)
+}
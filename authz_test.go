@@ -0,0 +1,84 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authorize returns the context authorized by the engine for the method with the roles as incoming metadata
+func authorize(t *testing.T, r RBAC, method string, roles ...string) context.Context {
+	t.Helper()
+	md := metadata.MD{}
+	for _, v := range roles {
+		md.Append("role", v)
+	}
+	ctx, err := r.Authorize(metadata.NewIncomingContext(context.Background(), md), method)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func TestRequire(t *testing.T) {
+	r := newTestRBAC(t)
+	read := NewGRPCPermission("test.TestService", "Read")
+	write := NewGRPCPermission("test.TestService", "Write")
+	public := NewGRPCPermission("test.TestService", "Public")
+	tests := []struct {
+		name string
+		ctx  context.Context
+		perm Permission
+		want codes.Code
+	}{
+		{name: "granted", ctx: authorize(t, r, readMethod, "reader"), perm: read},
+		{name: "inherited", ctx: authorize(t, r, readMethod, "admin"), perm: write},
+		{name: "denied", ctx: authorize(t, r, readMethod, "reader"), perm: write, want: codes.PermissionDenied},
+		{name: "public", ctx: authorize(t, r, publicMethod), perm: public},
+		{name: "anonymous", ctx: authorize(t, r, publicMethod), perm: read, want: codes.Unauthenticated},
+		{name: "missing engine", ctx: context.Background(), perm: read, want: codes.Internal},
+		{name: "missing roles", ctx: context.WithValue(context.Background(), key{}, r), perm: read, want: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Require(tt.ctx, tt.perm)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			if got := Can(tt.ctx, tt.perm); got != (tt.want == codes.OK) {
+				t.Errorf("can: got %v", got)
+			}
+		})
+	}
+}
+
+func TestRolesFromContext(t *testing.T) {
+	r := newTestRBAC(t)
+	if _, ok := RolesFromContext(context.Background()); ok {
+		t.Error("expected no roles in the background context")
+	}
+	roles, ok := RolesFromContext(authorize(t, r, readMethod, "reader", "writer"))
+	if !ok || len(roles) != 2 || roles[0].ID() != "reader" || roles[1].ID() != "writer" {
+		t.Errorf("unexpected roles %v", roles)
+	}
+	if _, err := r.Authorize(context.Background(), readMethod); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected unauthenticated error, got %v", err)
+	}
+}
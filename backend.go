@@ -43,6 +43,7 @@ type RBACBackend interface {
 }
 
 func (r *rbac) SetParents(id string, parents ...string) error {
+	defer r.NotifyPolicyChange()
 	return r.rbac.SetParents(id, parents)
 }
 
@@ -51,18 +52,22 @@ func (r *rbac) GetParents(id string) ([]string, error) {
 }
 
 func (r *rbac) SetParent(id string, parent string) error {
+	defer r.NotifyPolicyChange()
 	return r.rbac.SetParent(id, parent)
 }
 
 func (r *rbac) RemoveParent(id string, parent string) error {
+	defer r.NotifyPolicyChange()
 	return r.rbac.RemoveParent(id, parent)
 }
 
 func (r *rbac) Add(role gorbac.Role) (err error) {
+	defer r.NotifyPolicyChange()
 	return r.rbac.Add(role)
 }
 
 func (r *rbac) Remove(id string) (err error) {
+	defer r.NotifyPolicyChange()
 	return r.rbac.Remove(id)
 }
 
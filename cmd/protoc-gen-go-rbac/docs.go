@@ -0,0 +1,355 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+	"text/template"
+
+	pgs "github.com/lyft/protoc-gen-star"
+)
+
+const (
+	// serviceOptionsPath is the FileDescriptorProto service field number
+	serviceOptionsPath = 6
+	// serviceOptionsField is the ServiceDescriptorProto options field number
+	serviceOptionsField = 3
+	// fileOptionsField is the FileDescriptorProto options field number
+	fileOptionsField = 8
+)
+
+type docRole struct {
+	ID          string
+	DisplayName string
+	Parents     []string
+	Description string
+	Deprecated  bool
+}
+
+type docMethod struct {
+	Name        string
+	Public      bool
+	Roles       []string
+	Inherited   []string
+	Description string
+}
+
+type docService struct {
+	Name        string
+	Description string
+	Methods     []*docMethod
+	Roles       []*docRole
+}
+
+type docFile struct {
+	Name     string
+	Package  string
+	Roles    []*docRole
+	Services []*docService
+}
+
+// graph is the roles inheritance graph of all the known files
+type graph struct {
+	parents map[string][]string
+	// roles are the roles by id, used for their descriptions
+	roles map[string]*role
+}
+
+// ancestors returns all the parents of the role, transitively
+func (g *graph) ancestors(id string) map[string]struct{} {
+	out := make(map[string]struct{})
+	var walk func(id string)
+	walk = func(id string) {
+		for _, v := range g.parents[id] {
+			if _, ok := out[v]; ok {
+				continue
+			}
+			out[v] = struct{}{}
+			walk(v)
+		}
+	}
+	walk(id)
+	return out
+}
+
+// inherited returns the roles inheriting from at least one of the roles, excluding the roles themselves
+func (g *graph) inherited(roles []string) []string {
+	direct := make(map[string]struct{})
+	for _, v := range roles {
+		direct[v] = struct{}{}
+	}
+	var out []string
+	for id := range g.parents {
+		if _, ok := direct[id]; ok {
+			continue
+		}
+		for v := range g.ancestors(id) {
+			if _, ok := direct[v]; ok {
+				out = append(out, id)
+				break
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// knownFiles returns the targets files, their packages files and their transitive imports
+func knownFiles(targets map[string]pgs.File) []pgs.File {
+	var out []pgs.File
+	seen := make(map[string]struct{})
+	add := func(fs ...pgs.File) {
+		for _, f := range fs {
+			if _, ok := seen[f.Name().String()]; ok {
+				continue
+			}
+			seen[f.Name().String()] = struct{}{}
+			out = append(out, f)
+		}
+	}
+	for _, f := range targets {
+		add(f.Package().Files()...)
+		add(f.TransitiveImports()...)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name().String() < out[j].Name().String()
+	})
+	return out
+}
+
+// buildGraph builds the roles inheritance graph and collects the roles descriptions from the files
+func (p *module) buildGraph(files []pgs.File) *graph {
+	g := &graph{parents: make(map[string][]string), roles: make(map[string]*role)}
+	add := func(r *role) {
+		if _, ok := g.parents[r.Value]; !ok || len(r.ParentIDs) != 0 {
+			g.parents[r.Value] = r.ParentIDs
+		}
+		if v, ok := g.roles[r.Value]; !ok || v.def == nil && v.description == "" {
+			g.roles[r.Value] = r
+		}
+	}
+	for _, f := range files {
+		for _, r := range p.fileRoles(f) {
+			add(r)
+		}
+		for _, s := range f.Services() {
+			for _, r := range p.serviceRoles(s) {
+				add(r)
+			}
+		}
+	}
+	return g
+}
+
+// roleDescriptions returns the roles descriptions found in the leading comments of the option
+// at the source code info path, written as "<role>: <description>" lines, e.g.
+//
+//	// admin: full access to the resources
+//	option (rbac.def) = {...};
+func roleDescriptions(f pgs.File, path ...int32) map[string]string {
+	out := make(map[string]string)
+	for _, l := range f.Descriptor().GetSourceCodeInfo().GetLocation() {
+		if !equalPath(l.GetPath(), path) {
+			continue
+		}
+		for _, v := range strings.Split(l.GetLeadingComments(), "\n") {
+			parts := strings.SplitN(v, ":", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			name, desc := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+			if name == "" || strings.ContainsAny(name, " \t") {
+				continue
+			}
+			out[name] = desc
+		}
+	}
+	return out
+}
+
+func equalPath(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// oneLine joins the comment lines
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func (p *module) docFile(f pgs.File, g *graph) *docFile {
+	d := &docFile{Name: f.Name().String(), Package: f.Package().ProtoName().String()}
+	docRoles := func(roles []*role) []*docRole {
+		var out []*docRole
+		for _, r := range roles {
+			if v, ok := g.roles[r.Value]; ok {
+				r = v
+			}
+			out = append(out, &docRole{
+				ID:          r.Value,
+				DisplayName: r.def.GetDisplayName(),
+				Parents:     g.parents[r.Value],
+				Description: oneLine(r.description),
+				Deprecated:  r.def.GetDeprecated(),
+			})
+		}
+		sort.Slice(out, func(i, j int) bool {
+			return out[i].ID < out[j].ID
+		})
+		return out
+	}
+	d.Roles = docRoles(p.fileRoles(f))
+	for _, s := range f.Services() {
+		roles := p.serviceRoles(s)
+		ds := &docService{
+			Name:        strings.TrimPrefix(s.FullyQualifiedName(), "."),
+			Description: oneLine(s.SourceCodeInfo().LeadingComments()),
+			Roles:       docRoles(roles),
+		}
+		public := make(map[string]struct{})
+		for _, v := range p.publicMethods(s) {
+			public[v] = struct{}{}
+		}
+		for _, m := range s.Methods() {
+			dm := &docMethod{Name: m.Name().String(), Description: oneLine(m.SourceCodeInfo().LeadingComments())}
+			_, dm.Public = public[dm.Name]
+			for _, r := range roles {
+				for _, v := range r.Perms {
+					if v == dm.Name {
+						dm.Roles = append(dm.Roles, r.Value)
+					}
+				}
+			}
+			sort.Strings(dm.Roles)
+			dm.Inherited = g.inherited(dm.Roles)
+			ds.Methods = append(ds.Methods, dm)
+		}
+		d.Services = append(d.Services, ds)
+	}
+	return d
+}
+
+func (p *module) generateDocs(f pgs.File, g *graph) {
+	d := p.docFile(f, g)
+	p.AddGeneratorTemplateFile(p.ctx.OutputPath(f).SetExt(".rbac.md").String(), mdTpl, d)
+	p.AddGeneratorTemplateFile(p.ctx.OutputPath(f).SetExt(".rbac.html").String(), htmlTpl, d)
+}
+
+var docsFuncs = map[string]interface{}{
+	"cell": func(s string) string {
+		return strings.Replace(s, "|", `\|`, -1)
+	},
+	"codes": func(s []string) string {
+		var out []string
+		for _, v := range s {
+			out = append(out, "`"+v+"`")
+		}
+		return strings.Join(out, ", ")
+	},
+}
+
+var mdTpl = template.Must(template.New("md").Funcs(docsFuncs).Parse(`<!-- Code generated by protoc-gen-go-rbac. DO NOT EDIT. -->
+# {{ .Name }} access matrix
+{{- with .Roles }}
+
+## {{ $.Package }} package roles
+
+| Role | Name | Parents | Description |
+|------|------|---------|-------------|
+{{- range . }}
+| ` + "`{{ .ID }}`" + `{{ if .Deprecated }} (deprecated){{ end }} | {{ cell .DisplayName }} | {{ codes .Parents }} | {{ cell .Description }} |
+{{- end }}
+{{- end }}
+{{- range .Services }}
+
+## {{ .Name }}
+{{- with .Description }}
+
+{{ . }}
+{{- end }}
+
+| Method | Public | Roles | Inherited roles | Description |
+|--------|--------|-------|-----------------|-------------|
+{{- range .Methods }}
+| {{ .Name }} | {{ if .Public }}yes{{ end }} | {{ codes .Roles }} | {{ codes .Inherited }} | {{ cell .Description }} |
+{{- end }}
+{{- with .Roles }}
+
+### Roles
+
+| Role | Name | Parents | Description |
+|------|------|---------|-------------|
+{{- range . }}
+| ` + "`{{ .ID }}`" + `{{ if .Deprecated }} (deprecated){{ end }} | {{ cell .DisplayName }} | {{ codes .Parents }} | {{ cell .Description }} |
+{{- end }}
+{{- end }}
+{{- end }}
+`))
+
+var htmlTpl = htmltemplate.Must(htmltemplate.New("html").Funcs(docsFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="generator" content="protoc-gen-go-rbac">
+<title>{{ .Name }} access matrix</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+code { white-space: nowrap; }
+</style>
+</head>
+<body>
+<h1>{{ .Name }} access matrix</h1>
+{{- with .Roles }}
+<h2>{{ $.Package }} package roles</h2>
+{{ template "roles" . }}
+{{- end }}
+{{- range .Services }}
+<h2>{{ .Name }}</h2>
+{{- with .Description }}
+<p>{{ . }}</p>
+{{- end }}
+<table>
+<tr><th>Method</th><th>Public</th><th>Roles</th><th>Inherited roles</th><th>Description</th></tr>
+{{- range .Methods }}
+<tr><td>{{ .Name }}</td><td>{{ if .Public }}yes{{ end }}</td><td>{{ range $i, $v := .Roles }}{{ if $i }}, {{ end }}<code>{{ $v }}</code>{{ end }}</td><td>{{ range $i, $v := .Inherited }}{{ if $i }}, {{ end }}<code>{{ $v }}</code>{{ end }}</td><td>{{ .Description }}</td></tr>
+{{- end }}
+</table>
+{{- with .Roles }}
+<h3>Roles</h3>
+{{ template "roles" . }}
+{{- end }}
+{{- end }}
+</body>
+</html>
+{{ define "roles" -}}
+<table>
+<tr><th>Role</th><th>Name</th><th>Parents</th><th>Description</th></tr>
+{{- range . }}
+<tr><td><code>{{ .ID }}</code>{{ if .Deprecated }} (deprecated){{ end }}</td><td>{{ .DisplayName }}</td><td>{{ range $i, $v := .Parents }}{{ if $i }}, {{ end }}<code>{{ $v }}</code>{{ end }}</td><td>{{ .Description }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+`))
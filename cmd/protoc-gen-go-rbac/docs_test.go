@@ -0,0 +1,56 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestDocs(t *testing.T) {
+	files := mustGenerate(t, "roles.proto", "paths=source_relative,docs=true")
+	md, ok := files["roles.pb.rbac.md"]
+	if !ok {
+		t.Fatal("roles.pb.rbac.md not generated")
+	}
+	contains(t, "roles.pb.rbac.md", md,
+		"# roles.proto access matrix",
+		"| `test.Auditor` |  | `test.ResourceService.Reader` | read the resources for audit purposes |",
+		"ResourceService manages the resources.",
+		"| Read |  | `test.Auditor`, `test.ResourceService.Reader` | `test.ResourceService.Admin`, `test.ResourceService.Legacy` | Read returns the resource. |",
+		"| Write |  | `test.ResourceService.Writer` | `test.ResourceService.Admin` |  |",
+		"| Health | yes |  |  |  |",
+		"| `test.ResourceService.Admin` | Administrator | `test.ResourceService.Writer`, `test.ResourceService.Reader` | Full access to the resources. |",
+		"| `test.ResourceService.Legacy` (deprecated) |",
+	)
+	html, ok := files["roles.pb.rbac.html"]
+	if !ok {
+		t.Fatal("roles.pb.rbac.html not generated")
+	}
+	contains(t, "roles.pb.rbac.html", html,
+		"<title>roles.proto access matrix</title>",
+		"<tr><td>Health</td><td>yes</td><td></td><td></td><td></td></tr>",
+		"<td><code>test.ResourceService.Admin</code></td><td>Administrator</td>",
+		"<p>ResourceService manages the resources.</p>",
+	)
+}
+
+func TestDocsDisabled(t *testing.T) {
+	files := mustGenerate(t, "roles.proto", "paths=source_relative")
+	for _, v := range []string{"roles.pb.rbac.md", "roles.pb.rbac.html"} {
+		if _, ok := files[v]; ok {
+			t.Errorf("%s generated without docs=true", v)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	pgs "github.com/lyft/protoc-gen-star"
+
+	"go.linka.cloud/grpc-rbac/rbac"
+)
+
+// lint returns the strict mode issues of the targets files:
+//   - methods without rbac.access roles, unless public
+//   - roles defined but granting no permission, directly or through their parents
+//   - roles inheritance cycles
+//   - roles names colliding after the CamelCase conversion
+//
+// The unknown parents are always reported by the roles resolution.
+func (p *module) lint(targets map[string]pgs.File) []string {
+	var issues []string
+	g := p.buildGraph(knownFiles(targets))
+	granted := make(map[string]struct{})
+	for _, f := range knownFiles(targets) {
+		for _, s := range f.Services() {
+			for _, r := range p.serviceRoles(s) {
+				if len(r.Perms) != 0 {
+					granted[r.Value] = struct{}{}
+				}
+			}
+			for _, m := range p.fields(s) {
+				for _, v := range m.Fields {
+					for _, id := range append(v.Read, v.Write...) {
+						granted[id] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+	unused := func(id string) bool {
+		if _, ok := granted[id]; ok {
+			return false
+		}
+		for v := range g.ancestors(id) {
+			if _, ok := granted[v]; ok {
+				return false
+			}
+		}
+		return true
+	}
+	var files []pgs.File
+	for _, f := range targets {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name().String() < files[j].Name().String()
+	})
+	for _, f := range files {
+		var def rbac.RoleDefinition
+		if _, err := f.Extension(rbac.E_FileDef, &def); err != nil {
+			p.Fail(err)
+		}
+		var names []string
+		for _, v := range def.Roles {
+			names = append(names, v.GetName())
+		}
+		for _, v := range collisions(names) {
+			issues = append(issues, fmt.Sprintf("%s: package roles %s collide", f.Name(), v))
+		}
+		for _, r := range p.fileRoles(f) {
+			if unused(r.Value) {
+				issues = append(issues, fmt.Sprintf("%s: package role %s is not granted any permission", f.Name(), r.raw))
+			}
+		}
+		for _, s := range f.Services() {
+			issues = append(issues, p.lintService(s, unused)...)
+		}
+	}
+	for _, v := range g.cycles() {
+		issues = append(issues, fmt.Sprintf("roles inheritance cycle: %s", strings.Join(v, " -> ")))
+	}
+	return issues
+}
+
+func (p *module) lintService(s pgs.Service, unused func(id string) bool) []string {
+	var issues []string
+	var def rbac.RoleDefinition
+	if _, err := s.Extension(rbac.E_Def, &def); err != nil {
+		p.Fail(err)
+	}
+	var names []string
+	for _, v := range def.Roles {
+		names = append(names, v.GetName())
+	}
+	for _, m := range s.Methods() {
+		o := &rbac.RBAC{}
+		if _, err := m.Extension(rbac.E_Access, o); err != nil {
+			p.Fail(err)
+		}
+		if len(o.Roles) == 0 && !o.GetPublic() {
+			issues = append(issues, fmt.Sprintf("%s: missing rbac.access roles", strings.TrimPrefix(m.FullyQualifiedName(), ".")))
+		}
+		for _, v := range o.Roles {
+			if !strings.Contains(v, ".") && p.packageRole(s.File(), v) == nil {
+				names = append(names, v)
+			}
+		}
+	}
+	for _, v := range collisions(names) {
+		issues = append(issues, fmt.Sprintf("%s: roles %s collide", strings.TrimPrefix(s.FullyQualifiedName(), "."), v))
+	}
+	for _, r := range p.serviceRoles(s) {
+		if r.local && unused(r.Value) {
+			issues = append(issues, fmt.Sprintf("%s: role %s is not granted any permission", strings.TrimPrefix(s.FullyQualifiedName(), "."), r.raw))
+		}
+	}
+	return issues
+}
+
+// collisions returns the distinct names having the same Go name, e.g. "read_only, readOnly"
+func collisions(names []string) []string {
+	byName := make(map[string][]string)
+	seen := make(map[string]struct{})
+	for _, v := range names {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		byName[goName(v)] = append(byName[goName(v)], v)
+	}
+	var out []string
+	for _, v := range byName {
+		if len(v) > 1 {
+			out = append(out, strings.Join(v, ", "))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// cycles returns the roles inheritance cycles, each starting and ending with its smallest role id
+func (g *graph) cycles() [][]string {
+	var out [][]string
+	seen := make(map[string]struct{})
+	var ids []string
+	for id := range g.parents {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		var path []string
+		var walk func(v string)
+		walk = func(v string) {
+			for i, vv := range path {
+				if vv != v {
+					continue
+				}
+				c := append(append([]string{}, path[i:]...), v)
+				if c[0] != id {
+					return
+				}
+				if _, ok := seen[strings.Join(c, " ")]; !ok {
+					seen[strings.Join(c, " ")] = struct{}{}
+					out = append(out, c)
+				}
+				return
+			}
+			if v < id {
+				return
+			}
+			path = append(path, v)
+			for _, vv := range g.parents[v] {
+				walk(vv)
+			}
+			path = path[:len(path)-1]
+		}
+		walk(id)
+	}
+	return out
+}
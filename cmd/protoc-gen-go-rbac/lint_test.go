@@ -0,0 +1,82 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		param   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "strict",
+			file:  "lint.proto",
+			param: "strict=true",
+			want: []string{
+				"lint.proto: package roles viewer, Viewer collide",
+				"lint.proto: package role viewer is not granted any permission",
+				"lint.LintService.Missing: missing rbac.access roles",
+				"lint.LintService: roles read_only, readOnly collide",
+				"lint.LintService: role readOnly is not granted any permission",
+				"lint.LintService: role unused is not granted any permission",
+				"roles inheritance cycle: lint.LintService.A -> lint.LintService.B -> lint.LintService.A",
+			},
+			wantErr: true,
+		},
+		{
+			name:  "not strict",
+			file:  "lint.proto",
+			param: "strict=false",
+		},
+		{
+			name:    "unknown parent",
+			file:    "parent.proto",
+			param:   "strict=false",
+			want:    []string{"parent.ParentService: unknown admin parent role missing"},
+			wantErr: true,
+		},
+		{
+			name:  "valid",
+			file:  "roles.proto",
+			param: "strict=true",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := generate(t, tt.file, tt.param)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			for _, v := range tt.want {
+				if !strings.Contains(err.Error(), v) {
+					t.Errorf("missing issue %q in %v", v, err)
+				}
+			}
+			if strings.Contains(err.Error(), "Health") {
+				t.Errorf("public method reported: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// pluginEnv makes the test binary run as the plugin, see generate
+const pluginEnv = "PROTOC_GEN_GO_RBAC_TEST"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(pluginEnv) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// generate runs the plugin with the given parameter on the testdata proto file
+// and returns the generated files content by name.
+// The descriptor sets are generated from the testdata protos with make gen-testdata.
+func generate(t *testing.T, file, param string) (map[string]string, error) {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", strings.TrimSuffix(file, ".proto")+".pb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(b, &set); err != nil {
+		t.Fatal(err)
+	}
+	in, err := proto.Marshal(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{file},
+		Parameter:      proto.String(param),
+		ProtoFile:      set.File,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), pluginEnv+"=1")
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	var res pluginpb.CodeGeneratorResponse
+	if err := proto.Unmarshal(stdout.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Error != nil {
+		return nil, errors.New(res.GetError())
+	}
+	files := make(map[string]string)
+	for _, v := range res.File {
+		files[v.GetName()] = v.GetContent()
+	}
+	return files, nil
+}
+
+// mustGenerate is generate failing the test on error.
+func mustGenerate(t *testing.T, file, param string) map[string]string {
+	t.Helper()
+	files, err := generate(t, file, param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// contains fails the test for each of the want strings not found in the generated content.
+func contains(t *testing.T, name, content string, want ...string) {
+	t.Helper()
+	for _, v := range want {
+		if !strings.Contains(content, v) {
+			t.Errorf("%s: missing %q", name, v)
+		}
+	}
+}
+
+var exports struct {
+	once  sync.Once
+	files map[string]string
+	err   error
+}
+
+// exportData returns the export data files of the generated code dependencies by import path, built with go list
+func exportData() (map[string]string, error) {
+	exports.once.Do(func() {
+		out, err := exec.Command("go", "list", "-export", "-deps", "-f", "{{ .ImportPath }}={{ .Export }}", "context", "go.linka.cloud/grpc-rbac").Output()
+		if err != nil {
+			exports.err = fmt.Errorf("go list: %v", err)
+			return
+		}
+		exports.files = make(map[string]string)
+		for _, v := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			if i := strings.Index(v, "="); i != -1 {
+				exports.files[v[:i]] = v[i+1:]
+			}
+		}
+	})
+	return exports.files, exports.err
+}
+
+// typeCheck fails the test if the generated Go file does not type-check
+func typeCheck(t *testing.T, name, content string) {
+	t.Helper()
+	files, err := exportData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, content, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		if v, ok := files[path]; ok && v != "" {
+			return os.Open(v)
+		}
+		return nil, fmt.Errorf("missing export data for %s", path)
+	})}
+	if _, err := conf.Check(f.Name.Name, fset, []*ast.File{f}, nil); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+}
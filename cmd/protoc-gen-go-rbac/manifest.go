@@ -0,0 +1,125 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	pgs "github.com/lyft/protoc-gen-star"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"go.linka.cloud/grpc-rbac/rbac"
+)
+
+const (
+	manifestJSON   = "json"
+	manifestBinary = "binary"
+)
+
+// manifest returns the rbac policy declared by the file options
+func (p *module) manifest(f pgs.File) *rbac.Manifest {
+	m := &rbac.Manifest{
+		File:    proto.String(f.Name().String()),
+		Package: proto.String(f.Package().ProtoName().String()),
+	}
+	roles := func(roles []*role) []*rbac.ManifestRole {
+		var out []*rbac.ManifestRole
+		for _, r := range roles {
+			mr := &rbac.ManifestRole{Id: proto.String(r.Value), Parents: r.ParentIDs, Labels: r.def.GetLabels()}
+			if r.description != "" {
+				mr.Description = proto.String(r.description)
+			}
+			if r.def.GetDisplayName() != "" {
+				mr.DisplayName = proto.String(r.def.GetDisplayName())
+			}
+			if r.def.GetDeprecated() {
+				mr.Deprecated = proto.Bool(true)
+			}
+			out = append(out, mr)
+		}
+		sort.Slice(out, func(i, j int) bool {
+			return out[i].GetId() < out[j].GetId()
+		})
+		return out
+	}
+	m.Roles = roles(p.fileRoles(f))
+	for _, s := range f.Services() {
+		name := strings.TrimPrefix(s.FullyQualifiedName(), ".")
+		grants := p.serviceRoles(s)
+		ms := &rbac.ManifestService{Name: proto.String(name), Roles: roles(grants)}
+		public := make(map[string]struct{})
+		for _, v := range p.publicMethods(s) {
+			public[v] = struct{}{}
+		}
+		for _, v := range s.Methods() {
+			mm := &rbac.ManifestMethod{
+				Name:       proto.String(v.Name().String()),
+				FullMethod: proto.String(fmt.Sprintf("/%s/%s", name, v.Name())),
+			}
+			if _, ok := public[v.Name().String()]; ok {
+				mm.Public = proto.Bool(true)
+			}
+			for _, r := range grants {
+				for _, vv := range r.Perms {
+					if vv == v.Name().String() {
+						mm.Roles = append(mm.Roles, r.Value)
+					}
+				}
+			}
+			sort.Strings(mm.Roles)
+			ms.Methods = append(ms.Methods, mm)
+		}
+		for _, v := range p.fields(s) {
+			mm := &rbac.ManifestMessage{Name: proto.String(v.Name)}
+			for _, f := range v.Fields {
+				mm.Fields = append(mm.Fields, &rbac.ManifestField{Name: proto.String(f.Name), Read: f.Read, Write: f.Write})
+			}
+			ms.Messages = append(ms.Messages, mm)
+		}
+		m.Services = append(m.Services, ms)
+	}
+	return m
+}
+
+// generateManifest adds the file manifest encoded with the manifest parameter format,
+// as <file>.pb.rbac.json or <file>.pb.rbac.binpb
+func (p *module) generateManifest(f pgs.File) {
+	m := p.manifest(f)
+	switch p.manifestFormat {
+	case manifestJSON:
+		b, err := protojson.Marshal(m)
+		if err != nil {
+			p.Fail(err)
+		}
+		// protojson output is not stable, so we re-indent it
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, b, "", "  "); err != nil {
+			p.Fail(err)
+		}
+		buf.WriteString("\n")
+		p.AddGeneratorFile(p.ctx.OutputPath(f).SetExt(".rbac.json").String(), buf.String())
+	case manifestBinary:
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+		if err != nil {
+			p.Fail(err)
+		}
+		p.AddGeneratorFile(p.ctx.OutputPath(f).SetExt(".rbac.binpb").String(), string(b))
+	}
+}
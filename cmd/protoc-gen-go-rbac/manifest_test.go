@@ -0,0 +1,96 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"go.linka.cloud/grpc-rbac/rbac"
+)
+
+func TestManifest(t *testing.T) {
+	files := mustGenerate(t, "roles.proto", "paths=source_relative,manifest=json")
+	b, ok := files["roles.pb.rbac.json"]
+	if !ok {
+		t.Fatalf("roles.pb.rbac.json not generated: %v", files)
+	}
+	m := &rbac.Manifest{}
+	if err := protojson.Unmarshal([]byte(b), m); err != nil {
+		t.Fatal(err)
+	}
+	if m.GetFile() != "roles.proto" || m.GetPackage() != "test" {
+		t.Errorf("unexpected file %q and package %q", m.GetFile(), m.GetPackage())
+	}
+	if len(m.GetRoles()) != 1 || m.GetRoles()[0].GetId() != "test.Auditor" || !reflect.DeepEqual(m.GetRoles()[0].GetParents(), []string{"test.ResourceService.Reader"}) {
+		t.Errorf("unexpected package roles: %v", m.GetRoles())
+	}
+	if len(m.GetServices()) != 2 {
+		t.Fatalf("expected 2 services, got %d", len(m.GetServices()))
+	}
+	s := m.GetServices()[0]
+	if s.GetName() != "test.ResourceService" {
+		t.Fatalf("unexpected service %s", s.GetName())
+	}
+	methods := make(map[string]*rbac.ManifestMethod)
+	for _, v := range s.GetMethods() {
+		methods[v.GetName()] = v
+	}
+	if v := methods["Read"]; v.GetFullMethod() != "/test.ResourceService/Read" || !reflect.DeepEqual(v.GetRoles(), []string{"test.Auditor", "test.ResourceService.Reader"}) {
+		t.Errorf("unexpected Read method: %v", v)
+	}
+	if v := methods["Health"]; !v.GetPublic() || len(v.GetRoles()) != 0 {
+		t.Errorf("unexpected Health method: %v", v)
+	}
+	roles := make(map[string]*rbac.ManifestRole)
+	for _, v := range s.GetRoles() {
+		roles[v.GetId()] = v
+	}
+	admin := roles["test.ResourceService.Admin"]
+	if !reflect.DeepEqual(admin.GetParents(), []string{"test.ResourceService.Writer", "test.ResourceService.Reader"}) {
+		t.Errorf("unexpected admin parents: %v", admin.GetParents())
+	}
+	if admin.GetDisplayName() != "Administrator" || admin.GetLabels()["tier"] != "privileged" {
+		t.Errorf("unexpected admin metadata: %v", admin)
+	}
+	if !roles["test.ResourceService.Legacy"].GetDeprecated() {
+		t.Error("expected legacy role to be deprecated")
+	}
+	if len(s.GetMessages()) != 1 || s.GetMessages()[0].GetName() != "test.Resource" || len(s.GetMessages()[0].GetFields()) != 2 {
+		t.Errorf("unexpected messages: %v", s.GetMessages())
+	}
+
+	files = mustGenerate(t, "roles.proto", "paths=source_relative,manifest=binary")
+	bin, ok := files["roles.pb.rbac.binpb"]
+	if !ok {
+		t.Fatalf("roles.pb.rbac.binpb not generated: %v", files)
+	}
+	mb := &rbac.Manifest{}
+	if err := proto.Unmarshal([]byte(bin), mb); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(m, mb) {
+		t.Error("json and binary manifests differ")
+	}
+}
+
+func TestManifestInvalidFormat(t *testing.T) {
+	if _, err := generate(t, "roles.proto", "manifest=yaml"); err == nil {
+		t.Fatal("expected unsupported manifest format error")
+	}
+}
@@ -34,8 +34,19 @@ func Module() *module {
 
 type module struct {
 	*pgs.ModuleBase
-	ctx pgsgo.Context
-	tpl *template.Template
+	ctx      pgsgo.Context
+	tpl      *template.Template
+	testsTpl *template.Template
+	// legacy uses the <ServiceName>.<Role> roles ids instead of the package qualified ones
+	legacy bool
+	// docs generates the Markdown and HTML access matrix documentation
+	docs bool
+	// tests generates the access matrices tests, see rbactest
+	tests bool
+	// strict fails the generation on the lint issues, see lint
+	strict bool
+	// manifestFormat is the policy manifest encoding, json or binary, no manifest is generated if empty
+	manifestFormat string
 }
 
 func (p *module) Name() string {
@@ -45,88 +56,189 @@ func (p *module) Name() string {
 func (p *module) InitContext(c pgs.BuildContext) {
 	p.ModuleBase.InitContext(c)
 	p.ctx = pgsgo.InitContext(c.Parameters())
-
-	type role struct {
-		Name    string
-		Value   string
-		Perms   []string
-		Parents []string
+	legacy, err := c.Parameters().Bool("legacy_role_ids")
+	if err != nil {
+		p.Fail(err)
+	}
+	p.legacy = legacy
+	docs, err := c.Parameters().Bool("docs")
+	if err != nil {
+		p.Fail(err)
+	}
+	p.docs = docs
+	tests, err := c.Parameters().Bool("tests")
+	if err != nil {
+		p.Fail(err)
+	}
+	p.tests = tests
+	strict, err := c.Parameters().Bool("strict")
+	if err != nil {
+		p.Fail(err)
+	}
+	p.strict = strict
+	p.manifestFormat = c.Parameters().Str("manifest")
+	switch p.manifestFormat {
+	case "", manifestJSON, manifestBinary:
+	default:
+		p.Failf("invalid manifest format %q: expected %q or %q", p.manifestFormat, manifestJSON, manifestBinary)
 	}
 
 	tpl := template.New("fields").Funcs(map[string]interface{}{
 		"package": p.ctx.PackageName,
 		"name":    p.ctx.Name,
-		"comment": func(s string) string {
-			var out string
-			parts := strings.Split(s, "\n")
-			for i, v := range parts {
-				if i == len(parts)-1 && v == "" {
-					return out
+		"comment": comment,
+		"legacy": func() bool {
+			return p.legacy
+		},
+		"strings": func(s []string) string {
+			return fmt.Sprintf("%#v", s)
+		},
+		"roles": func(s pgs.Service) []*role {
+			var out []*role
+			for _, v := range p.serviceRoles(s) {
+				if v.local {
+					out = append(out, v)
 				}
-				out += "//" + v + "\n"
 			}
 			return out
 		},
-		"roles": func(s pgs.Service) []*role {
-			roles := make(map[string]*role)
-			var def rbac.RoleDefinition
-			_, err := s.Extension(rbac.E_Def, &def)
+		"grants":    p.serviceRoles,
+		"public":    p.publicMethods,
+		"fileRoles": p.fileRoles,
+		"join":      strings.Join,
+		"fields":    p.fields,
+		"roleDoc":   roleDoc,
+		"unexport": func(s pgs.Name) string {
+			return strings.ToLower(s.String()[:1]) + s.String()[1:]
+		},
+		"roleInfo": roleInfo,
+	})
+	p.tpl = template.Must(tpl.Parse(fieldsTpl))
+	p.initTestsTemplate()
+}
+
+func comment(s string) string {
+	var out string
+	parts := strings.Split(s, "\n")
+	for i, v := range parts {
+		if i == len(parts)-1 && v == "" {
+			return out
+		}
+		out += "//" + v + "\n"
+	}
+	return out
+}
+
+type field struct {
+	Name  string
+	Read  []string
+	Write []string
+}
+
+type message struct {
+	Name   string
+	Fields []*field
+}
+
+// fields returns the field access rules of the service methods input and output messages, sorted by message name
+func (p *module) fields(s pgs.Service) []*message {
+	var out []*message
+	seen := make(map[string]struct{})
+	var walk func(m pgs.Message)
+	walk = func(m pgs.Message) {
+		if _, ok := seen[m.FullyQualifiedName()]; ok {
+			return
+		}
+		seen[m.FullyQualifiedName()] = struct{}{}
+		msg := &message{Name: strings.TrimPrefix(m.FullyQualifiedName(), ".")}
+		for _, f := range m.Fields() {
+			o := &rbac.Field{}
+			ok, err := f.Extension(rbac.E_Field, o)
 			if err != nil {
 				p.Fail(err)
 			}
-			for _, v := range def.Roles {
-				val := fmt.Sprintf("%s.%s", s.Name(), strings.Title(v.GetName()))
-				r := &role{
-					Name:  strings.Replace(strings.Title(strings.NewReplacer(".", " ", "-", " ", ":", " ", "_", " ").Replace(v.GetName())), " ", "", -1),
-					Value: val,
-				}
-				for _, vv := range v.Parents {
-					r.Parents = append(r.Parents, strings.Title(strings.Replace(strings.Title(strings.NewReplacer(".", " ", "-", " ", ":", " ", "_", " ").Replace(vv)), " ", "", -1)))
-				}
-				roles[val] = r
-			}
-			for _, m := range s.Methods() {
-				o := &rbac.RBAC{}
-				ok, err := m.Extension(rbac.E_Access, o)
-				if err != nil {
-					p.Fail(err)
-				}
-				if !ok {
-					continue
+			if ok && len(o.Roles)+len(o.Write) != 0 {
+				fd := &field{Name: f.Name().String()}
+				ref := func(name string) string {
+					v, ok := p.roleRef(s, name)
+					if !ok {
+						p.Failf("%s: unknown role %s", f.FullyQualifiedName(), name)
+					}
+					return v
 				}
 				for _, v := range o.Roles {
-					val := fmt.Sprintf("%s.%s", s.Name(), strings.Title(v))
-					if _, ok := roles[val]; !ok {
-						roles[val] = &role{
-							Name:  strings.Replace(strings.Title(strings.NewReplacer(".", " ", "-", " ", ":", " ", "_", " ").Replace(v)), " ", "", -1),
-							Value: val,
-						}
-					}
-					roles[val].Perms = append(roles[val].Perms, m.Name().String())
+					fd.Read = append(fd.Read, ref(v))
+				}
+				for _, v := range o.Write {
+					fd.Write = append(fd.Write, ref(v))
 				}
+				msg.Fields = append(msg.Fields, fd)
 			}
-			var out []*role
-			for _, v := range roles {
-				out = append(out, v)
+			t := f.Type()
+			switch {
+			case t.IsEmbed():
+				walk(t.Embed())
+			case (t.IsRepeated() || t.IsMap()) && t.Element().IsEmbed():
+				walk(t.Element().Embed())
 			}
-			sort.Slice(out, func(i, j int) bool {
-				return sort.StringsAreSorted([]string{out[i].Name, out[j].Name})
-			})
-			return out
-		},
+		}
+		if len(msg.Fields) != 0 {
+			out = append(out, msg)
+		}
+	}
+	for _, m := range s.Methods() {
+		walk(m.Input())
+		walk(m.Output())
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
 	})
-	p.tpl = template.Must(tpl.Parse(fieldsTpl))
+	return out
+}
+
+// roleID returns the id of the service role registered in the rbac engine
+func (p *module) roleID(s pgs.Service, name string) string {
+	if p.legacy {
+		return fmt.Sprintf("%s.%s", s.Name(), strings.Title(name))
+	}
+	return qualifiedRoleID(s, name)
+}
+
+// qualifiedRoleID returns the role id qualified by the service full proto name, e.g. example.ResourceService.Admin
+func qualifiedRoleID(s pgs.Service, name string) string {
+	return fmt.Sprintf("%s.%s", strings.TrimPrefix(s.FullyQualifiedName(), "."), strings.Title(name))
 }
 
 func (p *module) Execute(targets map[string]pgs.File, _ map[string]pgs.Package) []pgs.Artifact {
+	if p.strict {
+		if issues := p.lint(targets); len(issues) != 0 {
+			p.Failf("rbac lint:\n%s", strings.Join(issues, "\n"))
+		}
+	}
+	var g *graph
+	if p.docs {
+		g = p.buildGraph(knownFiles(targets))
+	}
 	for _, f := range targets {
 		p.generate(f)
+		if len(f.Services())+len(p.fileRoles(f)) == 0 {
+			continue
+		}
+		if g != nil {
+			p.generateDocs(f, g)
+		}
+		if p.manifestFormat != "" {
+			p.generateManifest(f)
+		}
+		if p.tests {
+			p.generateTests(f)
+		}
 	}
 	return p.Artifacts()
 }
 
 func (p *module) generate(f pgs.File) {
-	if len(f.Services()) == 0 {
+	if len(f.Services()) == 0 && len(p.fileRoles(f)) == 0 {
 		return
 	}
 	name := p.ctx.OutputPath(f).SetExt(".rbac.go")
@@ -141,9 +253,43 @@ const fieldsTpl = `{{ comment .SyntaxSourceCodeInfo.LeadingComments }}
 package {{ package . }}
 {{ $file := . }}
 import (
+	{{- if .Services }}
+	"context"
+	{{ end }}
 	grpc_rbac "go.linka.cloud/grpc-rbac"
 )
 
+{{- with fileRoles . }}
+// PackageRoles are the {{ $file.Package.ProtoName }} package shared roles
+var PackageRoles = struct {
+	{{- range . }}
+	{{ roleDoc . }}{{ .Name }} *grpc_rbac.DescribedRole
+	{{- end }}
+}{
+	{{- range . }}
+	{{ .Name }}: grpc_rbac.NewDescribedRole({{ roleInfo . }}),
+	{{- end }}
+}
+
+// RegisterPackageRoles registers the {{ $file.Package.ProtoName }} package shared roles to the rbac engine.
+// Their permissions are granted by the services permissions registration.
+func RegisterPackageRoles(rbac grpc_rbac.RBAC) error {
+	var errs []error
+	{{- range . }}
+	// Register {{ .Name }} role
+	if err := grpc_rbac.Inherit(rbac, {{ .Ref }}{{ range .Parents }}, {{ . }}{{ end }}); err != nil {
+		errs = append(errs, err)
+	}
+	{{- end }}
+	rbac.DescribeRoles(
+		{{- range . }}
+		PackageRoles.{{ .Name }}.Info(),
+		{{- end }}
+	)
+	return grpc_rbac.JoinErrors(errs...)
+}
+{{ end }}
+
 {{ range .Services }}
 {{- $svc := . }} 
 var {{ .Name }}Permissions = struct {
@@ -156,43 +302,127 @@ var {{ .Name }}Permissions = struct {
 	{{- end }}
 }
 
+// {{ .Name }}Method is a {{ .Name }} method full name
+type {{ .Name }}Method string
+
+const (
+	{{- range .Methods }}
+	{{ $svc.Name }}Method{{ name . }} {{ $svc.Name }}Method = "/{{ $file.Package.ProtoName }}.{{ $svc.Name }}/{{ .Name }}"
+	{{- end }}
+)
+
+// {{ .Name }}Authz checks the {{ .Name }} methods access of the caller roles stored in the context by the server interceptors
+var {{ .Name }}Authz = {{ unexport .Name }}Authz{}
+
+type {{ unexport .Name }}Authz struct{}
+{{ range .Methods }}
+// Can{{ name . }} reports whether the caller is allowed to call {{ .Name }}
+func ({{ unexport $svc.Name }}Authz) Can{{ name . }}(ctx context.Context) bool {
+	return grpc_rbac.Can(ctx, {{ $svc.Name }}Permissions.{{ name . }})
+}
+
+// Require{{ name . }} returns a PermissionDenied error if the caller is not allowed to call {{ .Name }}
+func ({{ unexport $svc.Name }}Authz) Require{{ name . }}(ctx context.Context) error {
+	return grpc_rbac.Require(ctx, {{ $svc.Name }}Permissions.{{ name . }})
+}
+{{ end }}
+// AllowedMethods returns the {{ .Name }} methods the caller is allowed to call
+func ({{ unexport .Name }}Authz) AllowedMethods(ctx context.Context) []{{ .Name }}Method {
+	var out []{{ .Name }}Method
+	{{- range .Methods }}
+	if grpc_rbac.Can(ctx, {{ $svc.Name }}Permissions.{{ name . }}) {
+		out = append(out, {{ $svc.Name }}Method{{ name . }})
+	}
+	{{- end }}
+	return out
+}
+
 var {{ .Name }}Roles = struct {
 	{{- range roles . }}
-	{{ .Name }} *grpc_rbac.StdRole
+	{{ roleDoc . }}{{ .Name }} *grpc_rbac.DescribedRole
 	{{- end }}
 }{
 	{{- range roles . }}
-	{{ .Name }}: grpc_rbac.NewStdRole("{{ .Value }}"),
+	{{ .Name }}: grpc_rbac.NewDescribedRole({{ roleInfo . }}),
 	{{- end }}
 }
 
-func Register{{ .Name }}Permissions(rbac grpc_rbac.RBAC) {
+{{- if legacy }}
+// {{ .Name }}RoleAliases maps the legacy {{ .Name }} roles ids to the package qualified ones
+var {{ .Name }}RoleAliases = map[string]string{
 	{{- range roles . }}
-	{{- $role := . }}
-	{{- if .Perms }}// Assign {{ .Name }} permissions{{ end }}
-	{{- range .Perms }}
-	if err := {{ $svc.Name }}Roles.{{ $role.Name }}.Assign({{ $svc.Name }}Permissions.{{ . }}); err != nil {
-		panic(err)
-	}
+	"{{ .Value }}": "{{ .Qualified }}",
 	{{- end }}
+}
+{{ end }}
+
+// Register{{ .Name }}Permissions registers the {{ .Name }} roles, permissions and rules to the rbac engine.
+// The roles are created for each engine if they do not exist yet, so it can be used with multiple engines.
+func Register{{ .Name }}Permissions(rbac grpc_rbac.RBAC) error {
+	var errs []error
+	{{- range grants . }}
 	// Register {{ .Name }} role
-	if err := rbac.Add({{ $svc.Name }}Roles.{{ .Name }}); err != nil {
-		panic(err)
+	{{- if .Perms }}
+	if err := grpc_rbac.Grant(rbac, {{ .Ref }},
+		{{- range .Perms }}
+		{{ $svc.Name }}Permissions.{{ . }},
+		{{- end }}
+	); err != nil {
+	{{- else }}
+	if err := grpc_rbac.Grant(rbac, {{ .Ref }}); err != nil {
+	{{- end }}
+		errs = append(errs, err)
 	}
-	
-	{{ end }}
-	{{- range roles . }}
-	{{- $role := . }}
-	{{- if .Parents }}// Assign {{ .Name }} parents{{ end }}
-	{{- range .Parents }}
-	if err := rbac.SetParent({{ $svc.Name }}Roles.{{ $role.Name }}.ID(), {{ $svc.Name }}Roles.{{ . }}.ID()); err != nil {
-		panic(err)
+	{{- end }}
+	{{ range grants . }}
+	{{- if .Parents }}
+	// Assign {{ .Name }} parents
+	if err := grpc_rbac.Inherit(rbac, {{ .Ref }}, {{ join .Parents ", " }}); err != nil {
+		errs = append(errs, err)
 	}
 	{{- end }}
+	{{- end }}
+	{{- with roles . }}
+
+	// Describe {{ $svc.Name }} roles
+	rbac.DescribeRoles(
+		{{- range . }}
+		{{ $svc.Name }}Roles.{{ .Name }}.Info(),
+		{{- end }}
+	)
+	{{- end }}
+	{{- range fields . }}
+
+	// Register {{ .Name }} fields rules
+	rbac.RegisterFields("{{ .Name }}", grpc_rbac.FieldRules{
+		{{- range .Fields }}
+		"{{ .Name }}": {
+			{{- if .Read }}Read: {{ strings .Read }}{{ end }}
+			{{- if and .Read .Write }}, {{ end }}
+			{{- if .Write }}Write: {{ strings .Write }}{{ end -}}
+		},
+		{{- end }}
+	})
 	{{ end }}
 
 	// Register {{ .Name }} Service rules
-	rbac.Register(&{{ .Name }}_ServiceDesc)
+	if err := rbac.RegisterMethods(
+		{{- range .Methods }}
+		string({{ $svc.Name }}Method{{ name . }}),
+		{{- end }}
+	); err != nil {
+		errs = append(errs, err)
+	}
+	{{- with public . }}
+
+	// Register {{ $svc.Name }} public methods
+	rbac.RegisterPublic(
+		{{- range . }}
+		{{ $svc.Name }}Permissions.{{ . }}.ID(),
+		{{- end }}
+	)
+	{{- end }}
+	return grpc_rbac.JoinErrors(errs...)
 }
 
 {{ end }}
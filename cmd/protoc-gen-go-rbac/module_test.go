@@ -0,0 +1,106 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRoleIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:  "qualified",
+			param: "paths=source_relative",
+			want: []string{
+				`ID: "test.ResourceService.Admin"`,
+				`ID: "test.ResourceService.Reader"`,
+				`ID: "test.Auditor"`,
+				`"owner":  {Read: []string{"test.ResourceService.Admin"}}`,
+			},
+			notWant: []string{
+				`ID: "ResourceService.Admin"`,
+				"RoleAliases",
+			},
+		},
+		{
+			name:  "legacy",
+			param: "paths=source_relative,legacy_role_ids=true",
+			want: []string{
+				`ID: "ResourceService.Admin"`,
+				`ID: "ResourceService.Reader"`,
+				"var ResourceServiceRoleAliases = map[string]string{",
+				`"ResourceService.Admin":  "test.ResourceService.Admin"`,
+				`"owner":  {Read: []string{"ResourceService.Admin"}}`,
+			},
+			notWant: []string{
+				`ID: "test.ResourceService.Admin"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := mustGenerate(t, "roles.proto", tt.param)
+			got, ok := files["roles.pb.rbac.go"]
+			if !ok {
+				t.Fatalf("roles.pb.rbac.go not generated: %v", files)
+			}
+			contains(t, "roles.pb.rbac.go", got, tt.want...)
+			for _, v := range tt.notWant {
+				if strings.Contains(got, v) {
+					t.Errorf("roles.pb.rbac.go: unexpected %q", v)
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidParameters(t *testing.T) {
+	if _, err := generate(t, "roles.proto", "legacy_role_ids=maybe"); err == nil {
+		t.Fatal("expected invalid legacy_role_ids parameter error")
+	}
+}
+
+func TestRegistrationErrors(t *testing.T) {
+	got := mustGenerate(t, "roles.proto", "paths=source_relative")["roles.pb.rbac.go"]
+	contains(t, "roles.pb.rbac.go", got,
+		"func RegisterPackageRoles(rbac grpc_rbac.RBAC) error {",
+		"func RegisterResourceServicePermissions(rbac grpc_rbac.RBAC) error {",
+		"func RegisterAuditServicePermissions(rbac grpc_rbac.RBAC) error {",
+		"errs = append(errs, err)",
+		"return grpc_rbac.JoinErrors(errs...)",
+	)
+	if strings.Contains(got, "panic(") {
+		t.Error("roles.pb.rbac.go: generated registration must not panic")
+	}
+}
+
+func TestAuthzHelpers(t *testing.T) {
+	got := mustGenerate(t, "roles.proto", "paths=source_relative")["roles.pb.rbac.go"]
+	contains(t, "roles.pb.rbac.go", got,
+		"type ResourceServiceMethod string",
+		`ResourceServiceMethodRead   ResourceServiceMethod = "/test.ResourceService/Read"`,
+		"var ResourceServiceAuthz = resourceServiceAuthz{}",
+		"func (resourceServiceAuthz) CanRead(ctx context.Context) bool {\n\treturn grpc_rbac.Can(ctx, ResourceServicePermissions.Read)",
+		"func (resourceServiceAuthz) RequireWrite(ctx context.Context) error {\n\treturn grpc_rbac.Require(ctx, ResourceServicePermissions.Write)",
+		"func (resourceServiceAuthz) AllowedMethods(ctx context.Context) []ResourceServiceMethod {",
+		"func (auditServiceAuthz) CanLog(ctx context.Context) bool {",
+	)
+}
@@ -0,0 +1,394 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	pgs "github.com/lyft/protoc-gen-star"
+
+	"go.linka.cloud/grpc-rbac/rbac"
+)
+
+type role struct {
+	// Name is the role Go name
+	Name string
+	// Value is the role id
+	Value string
+	// Qualified is the role package qualified id
+	Qualified string
+	// Ref is the Go expression returning the role id
+	Ref string
+	// Perms are the method names the role is granted
+	Perms []string
+	// Parents are the Go expressions returning the role parents ids
+	Parents []string
+	// ParentIDs are the role parents ids
+	ParentIDs []string
+
+	raw   string
+	local bool
+	// def is the role definition options, nil for the roles only used by the rbac.access options
+	def *rbac.Role
+	// description is the role definition description, or the one found in the option comments, see roleDescriptions
+	description string
+}
+
+// pkgRole is a package shared role defined by the rbac.file_def option
+type pkgRole struct {
+	name    string
+	id      string
+	parents []string
+	file    pgs.File
+	def     *rbac.Role
+}
+
+func goName(name string) string {
+	return strings.Replace(strings.Title(strings.NewReplacer(".", " ", "-", " ", ":", " ", "_", " ").Replace(name)), " ", "", -1)
+}
+
+// packageRoles returns the package shared roles visible from the file, i.e. defined in the file package
+// or in the imported files, by "<package>.<name>"
+func (p *module) packageRoles(f pgs.File) map[string]*pkgRole {
+	out := make(map[string]*pkgRole)
+	files := make(map[string]pgs.File)
+	seen := make(map[string]struct{})
+	var walk func(f pgs.File)
+	walk = func(f pgs.File) {
+		if _, ok := seen[f.Name().String()]; ok {
+			return
+		}
+		seen[f.Name().String()] = struct{}{}
+		var def rbac.RoleDefinition
+		ok, err := f.Extension(rbac.E_FileDef, &def)
+		if err != nil {
+			p.Fail(err)
+		}
+		pkg := f.Package().ProtoName().String()
+		if ok && len(def.Roles) != 0 {
+			if o, ok := files[pkg]; ok {
+				p.Failf("%s: package %s roles are already defined in %s", f.Name(), pkg, o.Name())
+			}
+			files[pkg] = f
+			for _, v := range def.Roles {
+				out[pkg+"."+v.GetName()] = &pkgRole{
+					name:    v.GetName(),
+					id:      fmt.Sprintf("%s.%s", pkg, strings.Title(v.GetName())),
+					parents: v.Parents,
+					file:    f,
+					def:     v,
+				}
+			}
+		}
+		for _, v := range f.Imports() {
+			walk(v)
+		}
+	}
+	for _, v := range f.Package().Files() {
+		walk(v)
+	}
+	return out
+}
+
+// packageRole returns the package shared role referenced by name, either unqualified for the file package roles
+// or qualified by its package, from the file f
+func (p *module) packageRole(f pgs.File, name string) *role {
+	key := name
+	if !strings.Contains(name, ".") {
+		key = f.Package().ProtoName().String() + "." + name
+	}
+	r, ok := p.packageRoles(f)[key]
+	if !ok {
+		return nil
+	}
+	ref := strconv.Quote(r.id)
+	if p.ctx.ImportPath(r.file) == p.ctx.ImportPath(f) {
+		ref = fmt.Sprintf("PackageRoles.%s.ID()", goName(r.name))
+	}
+	desc := r.def.GetDescription()
+	if desc == "" {
+		desc = roleDescriptions(r.file, fileOptionsField, rbac.E_FileDef.Field)[r.name]
+	}
+	return &role{Name: goName(r.name), Value: r.id, Qualified: r.id, Ref: ref, raw: r.name, def: r.def, description: desc}
+}
+
+func (p *module) localRole(s pgs.Service, name string) *role {
+	return &role{
+		Name:      goName(name),
+		Value:     p.roleID(s, name),
+		Qualified: qualifiedRoleID(s, name),
+		Ref:       fmt.Sprintf("%sRoles.%s.ID()", s.Name(), goName(name)),
+		raw:       name,
+		local:     true,
+	}
+}
+
+// serviceRoles returns the service roles and the other roles the service grants permissions to, sorted by name.
+// The roles names are resolved first against the service local roles, see localRoles,
+// then against the package shared roles and the other services roles, see role.
+func (p *module) serviceRoles(s pgs.Service) []*role {
+	roles := make(map[string]*role)
+	local := make(map[string]*role)
+	for _, r := range p.localRoles(s) {
+		local[r.raw] = r
+		roles[r.Value] = r
+	}
+	resolve := func(name string) *role {
+		if r, ok := local[name]; ok {
+			return r
+		}
+		r := p.role(s.File(), name)
+		if r == nil {
+			return nil
+		}
+		if v, ok := roles[r.Value]; ok {
+			return v
+		}
+		return r
+	}
+	for _, m := range s.Methods() {
+		o := &rbac.RBAC{}
+		ok, err := m.Extension(rbac.E_Access, o)
+		if err != nil {
+			p.Fail(err)
+		}
+		if !ok {
+			continue
+		}
+		for _, v := range o.Roles {
+			r := resolve(v)
+			if r == nil {
+				p.Failf("%s: unknown role %s", m.FullyQualifiedName(), v)
+			}
+			roles[r.Value] = r
+			r.Perms = append(r.Perms, m.Name().String())
+		}
+	}
+	for _, r := range local {
+		for _, v := range r.def.GetParents() {
+			pr := resolve(v)
+			if pr == nil {
+				p.Failf("%s: unknown %s parent role %s", s.FullyQualifiedName(), r.raw, v)
+			}
+			r.Parents = append(r.Parents, pr.Ref)
+			r.ParentIDs = append(r.ParentIDs, pr.Value)
+		}
+	}
+	return sortRoles(roles)
+}
+
+// localRoles returns the roles defined by the service rbac.def option and the unknown unqualified names
+// used in its rbac.access options, which are service roles too.
+// The other roles are not resolved, so that services can reference each other roles without recursing.
+func (p *module) localRoles(s pgs.Service) []*role {
+	local := make(map[string]*role)
+	var def rbac.RoleDefinition
+	if _, err := s.Extension(rbac.E_Def, &def); err != nil {
+		p.Fail(err)
+	}
+	for _, v := range def.Roles {
+		r := p.localRole(s, v.GetName())
+		r.def = v
+		local[v.GetName()] = r
+	}
+	for _, m := range s.Methods() {
+		o := &rbac.RBAC{}
+		if _, err := m.Extension(rbac.E_Access, o); err != nil {
+			p.Fail(err)
+		}
+		for _, v := range o.Roles {
+			if _, ok := local[v]; ok || strings.Contains(v, ".") || p.packageRole(s.File(), v) != nil {
+				continue
+			}
+			local[v] = p.localRole(s, v)
+		}
+	}
+	descs := p.serviceRoleDescriptions(s)
+	for _, r := range local {
+		if r.description = r.def.GetDescription(); r.description == "" {
+			r.description = descs[r.raw]
+		}
+	}
+	return sortRoles(local)
+}
+
+// sortRoles returns the roles sorted by name, then by id as roles of different services may have the same name
+func sortRoles(roles map[string]*role) []*role {
+	var out []*role
+	for _, v := range roles {
+		out = append(out, v)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Value < out[j].Value
+	})
+	return out
+}
+
+// roleRef returns the id of the role referenced by name from the service, resolved as the rbac.access roles,
+// and false if the name is qualified but the role is unknown
+func (p *module) roleRef(s pgs.Service, name string) (string, bool) {
+	for _, v := range p.localRoles(s) {
+		if v.raw == name {
+			return v.Value, true
+		}
+	}
+	if r := p.role(s.File(), name); r != nil {
+		return r.Value, true
+	}
+	if strings.Contains(name, ".") {
+		return "", false
+	}
+	return p.roleID(s, name), true
+}
+
+// fileRoles returns the package shared roles defined in the file, see role for the parents resolution.
+func (p *module) fileRoles(f pgs.File) []*role {
+	var def rbac.RoleDefinition
+	if _, err := f.Extension(rbac.E_FileDef, &def); err != nil {
+		p.Fail(err)
+	}
+	var out []*role
+	for _, v := range def.Roles {
+		r := p.packageRole(f, v.GetName())
+		for _, vv := range v.Parents {
+			pr := p.role(f, vv)
+			if pr == nil {
+				p.Failf("%s: unknown %s parent role %s", f.Name(), v.GetName(), vv)
+			}
+			r.Parents = append(r.Parents, pr.Ref)
+			r.ParentIDs = append(r.ParentIDs, pr.Value)
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// role returns the role referenced by name from the file f, either:
+//   - a package shared role, unqualified for the file package or as "<package>.<role>"
+//   - a service role, as "<Service>.<role>" for the file package services or as "<package>.<Service>.<role>"
+//
+// The referenced roles must be defined in the file package or in the imported files.
+func (p *module) role(f pgs.File, name string) *role {
+	if r := p.packageRole(f, name); r != nil {
+		return r
+	}
+	return p.serviceRole(f, name)
+}
+
+// serviceRole returns the service role referenced as "<Service>.<role>" or "<package>.<Service>.<role>".
+// The role is not local to the referencing services, only its own service declares it.
+func (p *module) serviceRole(f pgs.File, name string) *role {
+	i := strings.LastIndex(name, ".")
+	if i == -1 {
+		return nil
+	}
+	svc, name := name[:i], name[i+1:]
+	files := append(f.Package().Files(), f.TransitiveImports()...)
+	for _, v := range files {
+		for _, s := range v.Services() {
+			fqn := strings.TrimPrefix(s.FullyQualifiedName(), ".")
+			if fqn != svc && (s.Package().ProtoName() != f.Package().ProtoName() || s.Name().String() != svc) {
+				continue
+			}
+			for _, r := range p.localRoles(s) {
+				if r.raw != name && r.Name != name {
+					continue
+				}
+				c := *r
+				c.local = false
+				if p.ctx.ImportPath(v) != p.ctx.ImportPath(f) {
+					c.Ref = strconv.Quote(c.Value)
+				}
+				return &c
+			}
+		}
+	}
+	return nil
+}
+
+// publicMethods returns the service methods marked as public by the rbac.access option
+func (p *module) publicMethods(s pgs.Service) []string {
+	var out []string
+	for _, m := range s.Methods() {
+		o := &rbac.RBAC{}
+		if _, err := m.Extension(rbac.E_Access, o); err != nil {
+			p.Fail(err)
+		}
+		if o.GetPublic() {
+			out = append(out, m.Name().String())
+		}
+	}
+	return out
+}
+
+// roleDoc returns the role Go doc comment, followed by a new line, if the role has a description or is deprecated
+func roleDoc(r *role) string {
+	var out string
+	for _, v := range strings.Split(strings.TrimSpace(r.description), "\n") {
+		if v != "" {
+			out += "// " + strings.TrimSpace(v) + "\n"
+		}
+	}
+	if r.def.GetDeprecated() {
+		if out != "" {
+			out += "//\n"
+		}
+		out += "// Deprecated: the " + r.Value + " role is deprecated.\n"
+	}
+	return out
+}
+
+// roleInfo returns the Go expression of the role grpc_rbac.RoleInfo
+func roleInfo(r *role) string {
+	out := fmt.Sprintf("grpc_rbac.RoleInfo{ID: %q", r.Value)
+	if v := r.def.GetDisplayName(); v != "" {
+		out += fmt.Sprintf(", DisplayName: %q", v)
+	}
+	if v := r.description; v != "" {
+		out += fmt.Sprintf(", Description: %q", v)
+	}
+	if r.def.GetDeprecated() {
+		out += ", Deprecated: true"
+	}
+	if len(r.def.GetLabels()) != 0 {
+		var keys []string
+		for k := range r.def.GetLabels() {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var labels []string
+		for _, k := range keys {
+			labels = append(labels, fmt.Sprintf("%q: %q", k, r.def.GetLabels()[k]))
+		}
+		out += fmt.Sprintf(", Labels: map[string]string{%s}", strings.Join(labels, ", "))
+	}
+	return out + "}"
+}
+
+// serviceRoleDescriptions returns the service roles descriptions found in the rbac.def option comments
+func (p *module) serviceRoleDescriptions(s pgs.Service) map[string]string {
+	for i, v := range s.File().Services() {
+		if v == s {
+			return roleDescriptions(s.File(), serviceOptionsPath, int32(i), serviceOptionsField, rbac.E_Def.Field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPackageRoles(t *testing.T) {
+	got := mustGenerate(t, "roles.proto", "paths=source_relative")["roles.pb.rbac.go"]
+	contains(t, "roles.pb.rbac.go", got,
+		"var PackageRoles = struct {",
+		`Auditor: grpc_rbac.NewDescribedRole(grpc_rbac.RoleInfo{ID: "test.Auditor"`,
+		"grpc_rbac.Inherit(rbac, PackageRoles.Auditor.ID(), ResourceServiceRoles.Reader.ID())",
+		"grpc_rbac.Grant(rbac, PackageRoles.Auditor.ID(),\n\t\tResourceServicePermissions.Read,",
+		"grpc_rbac.Grant(rbac, PackageRoles.Auditor.ID(),\n\t\tAuditServicePermissions.Log,",
+		`"status": {Write: []string{"test.ResourceService.Admin", "test.Auditor"}}`,
+	)
+	if n := strings.Count(got, "func RegisterPackageRoles("); n != 1 {
+		t.Errorf("expected RegisterPackageRoles to be generated once, got %d", n)
+	}
+}
+
+func TestServiceRolesOrder(t *testing.T) {
+	want := []string{
+		"// Register Admin role",
+		"// Register Auditor role",
+		"// Register Legacy role",
+		"// Register Reader role",
+		"// Register Writer role",
+	}
+	var prev string
+	for i := 0; i < 5; i++ {
+		got := mustGenerate(t, "roles.proto", "paths=source_relative")["roles.pb.rbac.go"]
+		if prev != "" && got != prev {
+			t.Fatal("generated code is not stable across runs")
+		}
+		prev = got
+		body := got[strings.Index(got, "func RegisterResourceServicePermissions("):]
+		last := -1
+		for _, v := range want {
+			i := strings.Index(body, v)
+			if i <= last {
+				t.Fatalf("%q is not sorted by name", v)
+			}
+			last = i
+		}
+	}
+}
+
+func TestServiceRolesSameName(t *testing.T) {
+	// the local reader role and the granted test.ResourceService.reader one have the same Go name
+	want := mustGenerate(t, "order.proto", "paths=source_relative")["order.pb.rbac.go"]
+	for i := 0; i < 8; i++ {
+		if got := mustGenerate(t, "order.proto", "paths=source_relative")["order.pb.rbac.go"]; got != want {
+			t.Fatal("generated code is not stable across runs")
+		}
+	}
+	i, j := strings.Index(want, "OrderServiceRoles.Reader.ID(),\n"), strings.Index(want, "\"test.ResourceService.Reader\",\n")
+	if i == -1 || j == -1 || i > j {
+		t.Errorf("expected the roles with the same name to be sorted by id")
+	}
+}
+
+func TestCrossServiceRoles(t *testing.T) {
+	t.Run("mutual", func(t *testing.T) {
+		got := mustGenerate(t, "mutual.proto", "paths=source_relative")["mutual.pb.rbac.go"]
+		typeCheck(t, "mutual.pb.rbac.go", got)
+		contains(t, "mutual.pb.rbac.go", got,
+			"grpc_rbac.Grant(rbac, TwoRoles.Admin.ID(),\n\t\tOnePermissions.Get,",
+			"grpc_rbac.Grant(rbac, OneRoles.Admin.ID(),\n\t\tTwoPermissions.Get,",
+			"var OneRoles = struct {\n\tAdmin *grpc_rbac.DescribedRole\n}",
+			"rbac.DescribeRoles(\n\t\tOneRoles.Admin.Info(),\n\t)",
+		)
+	})
+	t.Run("imported", func(t *testing.T) {
+		got := mustGenerate(t, "consumer.proto", "paths=source_relative")["consumer.pb.rbac.go"]
+		typeCheck(t, "consumer.pb.rbac.go", got)
+		contains(t, "consumer.pb.rbac.go", got,
+			`grpc_rbac.Inherit(rbac, PackageRoles.Viewer.ID(), "test.ResourceService.Reader", "test.Auditor")`,
+			`grpc_rbac.Inherit(rbac, ConsumerServiceRoles.Operator.ID(), "test.ResourceService.Admin")`,
+			"grpc_rbac.Grant(rbac, \"test.Auditor\",\n\t\tConsumerServicePermissions.Get,",
+			"var ConsumerServiceRoles = struct {\n\tOperator *grpc_rbac.DescribedRole\n}",
+			`"secret": {Read: []string{"test.ResourceService.Admin"}, Write: []string{"consumer.ConsumerService.Operator"}}`,
+		)
+	})
+	t.Run("same name", func(t *testing.T) {
+		got := mustGenerate(t, "order.proto", "paths=source_relative")["order.pb.rbac.go"]
+		typeCheck(t, "order.pb.rbac.go", got)
+		contains(t, "order.pb.rbac.go", got,
+			"var OrderServiceRoles = struct {\n\tReader *grpc_rbac.DescribedRole\n}",
+			"grpc_rbac.Grant(rbac, \"test.ResourceService.Reader\",\n\t\tOrderServicePermissions.Get,",
+		)
+	})
+	t.Run("unknown", func(t *testing.T) {
+		_, err := generate(t, "unknown.proto", "paths=source_relative")
+		if err == nil {
+			t.Fatal("expected unknown role error")
+		}
+		if !strings.Contains(err.Error(), "unknown role Two.admin") {
+			t.Fatalf("expected unknown role Two.admin error, got %v", err)
+		}
+	})
+	t.Run("unknown field role", func(t *testing.T) {
+		_, err := generate(t, "field.proto", "paths=source_relative")
+		if err == nil {
+			t.Fatal("expected unknown role error")
+		}
+		if !strings.Contains(err.Error(), "field.Item.secret: unknown role test.NoSuch.admin") {
+			t.Fatalf("expected unknown field role error, got %v", err)
+		}
+	})
+}
+
+func TestRoleDescriptions(t *testing.T) {
+	got := mustGenerate(t, "roles.proto", "paths=source_relative")["roles.pb.rbac.go"]
+	contains(t, "roles.pb.rbac.go", got,
+		"\t// read the resources for audit purposes\n\tAuditor *grpc_rbac.DescribedRole",
+		"\t// Full access to the resources.\n\tAdmin *grpc_rbac.DescribedRole",
+		"\t// Deprecated: the test.ResourceService.Legacy role is deprecated.\n\tLegacy *grpc_rbac.DescribedRole",
+		"\t// read the resources\n\tReader *grpc_rbac.DescribedRole",
+		`grpc_rbac.RoleInfo{ID: "test.ResourceService.Admin", DisplayName: "Administrator", Description: "Full access to the resources.", Labels: map[string]string{"tier": "privileged"}}`,
+		`grpc_rbac.RoleInfo{ID: "test.ResourceService.Legacy", Deprecated: true}`,
+		`grpc_rbac.RoleInfo{ID: "test.ResourceService.Writer"}`,
+		"rbac.DescribeRoles(\n\t\tResourceServiceRoles.Admin.Info(),",
+	)
+}
@@ -0,0 +1,174 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sort"
+	"text/template"
+
+	pgs "github.com/lyft/protoc-gen-star"
+)
+
+type testCase struct {
+	Role    string
+	Method  string
+	Allowed bool
+}
+
+// hasPackageRoles reports whether the file Go package defines the package shared roles, i.e. RegisterPackageRoles
+func (p *module) hasPackageRoles(f pgs.File) bool {
+	for _, v := range f.Package().Files() {
+		if p.ctx.ImportPath(v) == p.ctx.ImportPath(f) && len(p.fileRoles(v)) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// testGraph returns the roles inheritance graph registered by the file generated test, i.e. by the Go package
+// RegisterPackageRoles and the file services Register<Service>Permissions functions
+func (p *module) testGraph(f pgs.File) *graph {
+	g := &graph{parents: make(map[string][]string), roles: make(map[string]*role)}
+	add := func(r *role) {
+		if _, ok := g.parents[r.Value]; !ok || len(r.ParentIDs) != 0 {
+			g.parents[r.Value] = r.ParentIDs
+		}
+		for _, v := range r.ParentIDs {
+			if _, ok := g.parents[v]; !ok {
+				g.parents[v] = nil
+			}
+		}
+	}
+	for _, v := range f.Package().Files() {
+		if p.ctx.ImportPath(v) != p.ctx.ImportPath(f) {
+			continue
+		}
+		for _, r := range p.fileRoles(v) {
+			add(r)
+		}
+	}
+	for _, s := range f.Services() {
+		for _, r := range p.serviceRoles(s) {
+			add(r)
+		}
+	}
+	return g
+}
+
+// matrix returns the service role × method access matrix, including a caller without any role:
+// a role is allowed to call a method if the method is public, or if the role or one of its ancestors is granted the method
+func (p *module) matrix(s pgs.Service) []testCase {
+	g := p.testGraph(s.File())
+	var ids []string
+	for id := range g.parents {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	ids = append([]string{""}, ids...)
+	public := make(map[string]struct{})
+	for _, v := range p.publicMethods(s) {
+		public[v] = struct{}{}
+	}
+	granted := make(map[string]map[string]struct{})
+	for _, r := range p.serviceRoles(s) {
+		for _, v := range r.Perms {
+			if granted[v] == nil {
+				granted[v] = make(map[string]struct{})
+			}
+			granted[v][r.Value] = struct{}{}
+		}
+	}
+	var out []testCase
+	for _, m := range s.Methods() {
+		name := m.Name().String()
+		for _, id := range ids {
+			c := testCase{Role: id, Method: name}
+			_, c.Allowed = public[name]
+			if _, ok := granted[name][id]; ok {
+				c.Allowed = true
+			}
+			for v := range g.ancestors(id) {
+				if _, ok := granted[name][v]; ok {
+					c.Allowed = true
+				}
+			}
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// generateTests adds the <file>.pb.rbac_test.go access matrices tests
+func (p *module) generateTests(f pgs.File) {
+	if len(f.Services()) == 0 {
+		return
+	}
+	p.AddGeneratorTemplateFile(p.ctx.OutputPath(f).SetExt(".rbac_test.go").String(), p.testsTpl, f)
+}
+
+func (p *module) initTestsTemplate() {
+	p.testsTpl = template.Must(template.New("tests").Funcs(map[string]interface{}{
+		"package":         p.ctx.PackageName,
+		"comment":         comment,
+		"matrix":          p.matrix,
+		"hasPackageRoles": p.hasPackageRoles,
+	}).Parse(testsTpl))
+}
+
+const testsTpl = `{{ comment .SyntaxSourceCodeInfo.LeadingComments }}
+{{ range .SyntaxSourceCodeInfo.LeadingDetachedComments }}
+{{ comment . }}
+{{ end }}
+// Code generated by protoc-gen-go-rbac. DO NOT EDIT.
+package {{ package . }}
+
+import (
+	"testing"
+
+	grpc_rbac "go.linka.cloud/grpc-rbac"
+	"go.linka.cloud/grpc-rbac/rbactest"
+)
+{{ $file := . }}
+{{- range .Services }}
+
+// {{ .Name }}AccessMatrix is the {{ .Name }} role × method access matrix declared by the proto options,
+// an empty role is a caller without any role
+var {{ .Name }}AccessMatrix = []rbactest.Case{
+	{{- range matrix . }}
+	{Role: "{{ .Role }}", Method: "{{ .Method }}", Allowed: {{ .Allowed }}},
+	{{- end }}
+}
+
+// Check{{ .Name }}AccessMatrix checks the {{ .Name }} access matrix against the generated registration,
+// followed by the options policies, through an in-process channel
+func Check{{ .Name }}AccessMatrix(t *testing.T, opts ...rbactest.Option) {
+	t.Helper()
+	rbactest.Run(t, &{{ .Name }}_ServiceDesc, &Unimplemented{{ .Name }}Server{}, func(rbac grpc_rbac.RBAC) error {
+		return grpc_rbac.JoinErrors(
+			{{- if hasPackageRoles $file }}
+			RegisterPackageRoles(rbac),
+			{{- end }}
+			{{- range $file.Services }}
+			Register{{ .Name }}Permissions(rbac),
+			{{- end }}
+		)
+	}, {{ .Name }}AccessMatrix, opts...)
+}
+
+func Test{{ .Name }}AccessMatrix(t *testing.T) {
+	Check{{ .Name }}AccessMatrix(t)
+}
+{{- end }}
+`
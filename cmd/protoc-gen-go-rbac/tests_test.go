@@ -0,0 +1,43 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestGeneratedTests(t *testing.T) {
+	files := mustGenerate(t, "roles.proto", "paths=source_relative,tests=true")
+	got, ok := files["roles.pb.rbac_test.go"]
+	if !ok {
+		t.Fatalf("roles.pb.rbac_test.go not generated: %v", files)
+	}
+	contains(t, "roles.pb.rbac_test.go", got,
+		`{Role: "", Method: "Read", Allowed: false},`,
+		`{Role: "test.Auditor", Method: "Read", Allowed: true},`,
+		`{Role: "test.ResourceService.Legacy", Method: "Read", Allowed: true},`,
+		`{Role: "test.ResourceService.Admin", Method: "Write", Allowed: true},`,
+		`{Role: "test.ResourceService.Reader", Method: "Write", Allowed: false},`,
+		`{Role: "", Method: "Health", Allowed: true},`,
+		`{Role: "test.ResourceService.Admin", Method: "Log", Allowed: true},`,
+		`{Role: "test.ResourceService.Reader", Method: "Log", Allowed: false},`,
+		"\t\t\tRegisterPackageRoles(rbac),\n\t\t\tRegisterResourceServicePermissions(rbac),\n\t\t\tRegisterAuditServicePermissions(rbac),",
+		"func TestResourceServiceAccessMatrix(t *testing.T) {",
+		"func TestAuditServiceAccessMatrix(t *testing.T) {",
+	)
+	if _, ok := mustGenerate(t, "roles.proto", "paths=source_relative")["roles.pb.rbac_test.go"]; ok {
+		t.Error("roles.pb.rbac_test.go generated without tests=true")
+	}
+}
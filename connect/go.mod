@@ -0,0 +1,21 @@
+module go.linka.cloud/grpc-rbac/connect
+
+go 1.20
+
+require (
+	connectrpc.com/connect v1.16.1
+	go.linka.cloud/grpc-rbac v0.0.0-00010101000000-000000000000
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013
+	google.golang.org/grpc v1.47.0
+	google.golang.org/protobuf v1.33.0
+)
+
+require (
+	github.com/golang/protobuf v1.5.4 // indirect
+	github.com/mikespook/gorbac/v2 v2.3.3 // indirect
+	golang.org/x/net v0.21.0 // indirect
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
+
+replace go.linka.cloud/grpc-rbac => ./..
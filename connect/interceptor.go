@@ -0,0 +1,163 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package connect provides a connect interceptor backed by the grpc_rbac engine.
+package connect
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+
+	"connectrpc.com/connect"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	grpc_rbac "go.linka.cloud/grpc-rbac"
+)
+
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+// Interceptor authorizes the connect handlers calls with the rbac engine, as the gRPC server interceptors do:
+// the RoleFunc is called with the request headers as incoming metadata, the requests setting restricted fields
+// are denied and the restricted fields are cleared from the responses.
+// The handlers receive the caller roles in their context, see grpc_rbac.RolesFromContext.
+// The clients calls are not authorized.
+type Interceptor struct {
+	rbac grpc_rbac.RBAC
+}
+
+// NewInterceptor returns an Interceptor, to be used with connect.WithInterceptors.
+// The procedures must be registered with the rbac engine, e.g. with RBAC.RegisterMethods.
+func NewInterceptor(rbac grpc_rbac.RBAC) *Interceptor {
+	return &Interceptor{rbac: rbac}
+}
+
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		ctx, err := i.authorize(ctx, req.Spec().Procedure, req.Header())
+		if err != nil {
+			return nil, err
+		}
+		if err := i.rbac.CheckFields(ctx, req.Any()); err != nil {
+			return nil, connectError(err)
+		}
+		res, err := next(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if masked := i.rbac.MaskFields(ctx, res.Any()); masked != res.Any() {
+			return withMessage(res, masked), nil
+		}
+		return res, nil
+	}
+}
+
+// withMessage returns a response of the same type as res holding the message m, with the res headers and trailers.
+// The response message is not modified in place as it may be shared with the handler.
+func withMessage(res connect.AnyResponse, m any) connect.AnyResponse {
+	v := reflect.New(reflect.TypeOf(res).Elem())
+	v.Elem().FieldByName("Msg").Set(reflect.ValueOf(m))
+	out := v.Interface().(connect.AnyResponse)
+	for k, vv := range res.Header() {
+		out.Header()[k] = vv
+	}
+	for k, vv := range res.Trailer() {
+		out.Trailer()[k] = vv
+	}
+	return out
+}
+
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, err := i.authorize(ctx, conn.Spec().Procedure, conn.RequestHeader())
+		if err != nil {
+			return err
+		}
+		return next(ctx, &handlerConn{StreamingHandlerConn: conn, ctx: ctx, rbac: i.rbac})
+	}
+}
+
+// authorize authorizes the procedure call with the headers as incoming metadata, and returns the handler context
+func (i *Interceptor) authorize(ctx context.Context, procedure string, h http.Header) (context.Context, error) {
+	md := make(metadata.MD, len(h))
+	for k, v := range h {
+		md.Append(k, v...)
+	}
+	ctx, err := i.rbac.Authorize(metadata.NewIncomingContext(ctx, md), procedure)
+	if err != nil {
+		return nil, connectError(err)
+	}
+	return ctx, nil
+}
+
+// handlerConn checks the received messages fields and masks the sent ones
+type handlerConn struct {
+	connect.StreamingHandlerConn
+	ctx  context.Context
+	rbac grpc_rbac.RBAC
+}
+
+func (c *handlerConn) Receive(m any) error {
+	if err := c.StreamingHandlerConn.Receive(m); err != nil {
+		return err
+	}
+	if err := c.rbac.CheckFields(c.ctx, m); err != nil {
+		return connectError(err)
+	}
+	return nil
+}
+
+func (c *handlerConn) Send(m any) error {
+	return c.StreamingHandlerConn.Send(c.rbac.MaskFields(c.ctx, m))
+}
+
+// connectError converts the gRPC status error to a connect error with the same code, message and details
+func connectError(err error) error {
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	e := connect.NewError(connect.Code(s.Code()), errors.New(s.Message()))
+	for _, v := range s.Proto().GetDetails() {
+		if d, err := connect.NewErrorDetail(v); err == nil {
+			e.AddDetail(d)
+		}
+	}
+	return e
+}
+
+// DenialFromError returns the authorization denial carried by the connect error details, if any,
+// see grpc_rbac.DenialFromError
+func DenialFromError(err error) (*grpc_rbac.Denial, bool) {
+	var e *connect.Error
+	if !errors.As(err, &e) {
+		return grpc_rbac.DenialFromError(err)
+	}
+	s := &spb.Status{Code: int32(e.Code()), Message: e.Message()}
+	for _, v := range e.Details() {
+		s.Details = append(s.Details, &anypb.Any{TypeUrl: "type.googleapis.com/" + v.Type(), Value: v.Bytes()})
+	}
+	return grpc_rbac.DenialFromError(status.ErrorProto(s))
+}
@@ -0,0 +1,235 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connect
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"google.golang.org/grpc/metadata"
+
+	grpc_rbac "go.linka.cloud/grpc-rbac"
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+)
+
+const (
+	readMethod  = "/test.TestService/Read"
+	writeMethod = "/test.TestService/Write"
+	watchMethod = "/test.TestService/Watch"
+)
+
+// newEngine returns an engine resolving the roles from the incoming "role" metadata,
+// where reader can call Read and Watch, writer can call Write, and admin inherits both.
+// The Resource owner can only be read by admin and its status only written by admin.
+func newEngine(t *testing.T) grpc_rbac.RBAC {
+	t.Helper()
+	r := grpc_rbac.New(grpc_rbac.WithRoleFunc(func(ctx context.Context) ([]grpc_rbac.Role, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var roles []grpc_rbac.Role
+		for _, v := range md.Get("role") {
+			roles = append(roles, grpc_rbac.NewStdRole(v))
+		}
+		return roles, nil
+	}))
+	for _, err := range []error{
+		grpc_rbac.Grant(r, "reader", grpc_rbac.NewGRPCPermission("test.TestService", "Read"), grpc_rbac.NewGRPCPermission("test.TestService", "Watch")),
+		grpc_rbac.Grant(r, "writer", grpc_rbac.NewGRPCPermission("test.TestService", "Write")),
+		grpc_rbac.Inherit(r, "admin", "writer", "reader"),
+		r.RegisterMethods(readMethod, writeMethod, watchMethod),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.RegisterFields("test.Resource", grpc_rbac.FieldRules{
+		"owner":  {Read: []string{"admin"}},
+		"status": {Write: []string{"admin"}},
+	})
+	return r
+}
+
+type clients struct {
+	read  *connect.Client[testpb.Request, testpb.Response]
+	write *connect.Client[testpb.Request, testpb.Response]
+	watch *connect.Client[testpb.Request, testpb.Response]
+}
+
+// serve serves echo handlers for Read, Write and Watch with the engine interceptor and returns their clients.
+// The handlers fail if the caller roles are missing from their context.
+func serve(t *testing.T, r grpc_rbac.RBAC) clients {
+	t.Helper()
+	opt := connect.WithInterceptors(NewInterceptor(r))
+	unary := func(ctx context.Context, req *connect.Request[testpb.Request]) (*connect.Response[testpb.Response], error) {
+		if _, ok := grpc_rbac.RolesFromContext(ctx); !ok {
+			return nil, connect.NewError(connect.CodeInternal, nil)
+		}
+		res := connect.NewResponse(&testpb.Response{Resource: req.Msg.GetResource()})
+		res.Header().Set("x-test", "value")
+		return res, nil
+	}
+	mux := http.NewServeMux()
+	mux.Handle(readMethod, connect.NewUnaryHandler(readMethod, unary, opt))
+	mux.Handle(writeMethod, connect.NewUnaryHandler(writeMethod, unary, opt))
+	mux.Handle(watchMethod, connect.NewServerStreamHandler(watchMethod, func(ctx context.Context, req *connect.Request[testpb.Request], ss *connect.ServerStream[testpb.Response]) error {
+		for _, v := range req.Msg.GetResource().GetChildren() {
+			if err := ss.Send(&testpb.Response{Resource: v}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, opt))
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return clients{
+		read:  connect.NewClient[testpb.Request, testpb.Response](s.Client(), s.URL+readMethod),
+		write: connect.NewClient[testpb.Request, testpb.Response](s.Client(), s.URL+writeMethod),
+		watch: connect.NewClient[testpb.Request, testpb.Response](s.Client(), s.URL+watchMethod),
+	}
+}
+
+// request returns the request with the roles in the "role" header
+func request(msg *testpb.Request, roles ...string) *connect.Request[testpb.Request] {
+	req := connect.NewRequest(msg)
+	for _, v := range roles {
+		req.Header().Add("role", v)
+	}
+	return req
+}
+
+func TestUnary(t *testing.T) {
+	c := serve(t, newEngine(t))
+	resource := &testpb.Resource{Id: "1", Owner: "owner", Children: []*testpb.Resource{{Id: "2", Owner: "owner"}}}
+	tests := []struct {
+		name   string
+		client *connect.Client[testpb.Request, testpb.Response]
+		roles  []string
+		req    *testpb.Request
+		code   connect.Code
+		reason string
+		owner  string
+	}{
+		{name: "masked", client: c.read, roles: []string{"reader"}, req: &testpb.Request{Resource: resource}},
+		{name: "not masked", client: c.read, roles: []string{"admin"}, req: &testpb.Request{Resource: resource}, owner: "owner"},
+		{name: "unauthenticated", client: c.read, req: &testpb.Request{}, code: connect.CodeUnauthenticated},
+		{name: "method denied", client: c.write, roles: []string{"reader"}, req: &testpb.Request{}, code: connect.CodePermissionDenied, reason: grpc_rbac.ReasonMethodDenied},
+		{
+			name:   "field denied",
+			client: c.write,
+			roles:  []string{"writer"},
+			req:    &testpb.Request{Resource: &testpb.Resource{Status: testpb.Resource_ACTIVE}},
+			code:   connect.CodePermissionDenied,
+			reason: grpc_rbac.ReasonFieldDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := tt.client.CallUnary(context.Background(), request(tt.req, tt.roles...))
+			if connect.CodeOf(err) != tt.code && !(err == nil && tt.code == 0) {
+				t.Fatalf("got %v, want %v", err, tt.code)
+			}
+			if tt.code != 0 {
+				d, ok := DenialFromError(err)
+				if ok != (tt.reason != "") || ok && d.Reason != tt.reason {
+					t.Errorf("got denial %+v, want reason %q", d, tt.reason)
+				}
+				return
+			}
+			got := res.Msg.GetResource()
+			if got.GetOwner() != tt.owner || got.GetChildren()[0].GetOwner() != tt.owner {
+				t.Errorf("got owners %q and %q, want %q", got.GetOwner(), got.GetChildren()[0].GetOwner(), tt.owner)
+			}
+			if got.GetId() != "1" || got.GetChildren()[0].GetId() != "2" {
+				t.Errorf("unexpected resource %v", got)
+			}
+			if res.Header().Get("x-test") != "value" {
+				t.Error("missing response header")
+			}
+		})
+	}
+	if resource.GetOwner() != "owner" {
+		t.Error("request resource modified")
+	}
+}
+
+func TestUnarySharedResponse(t *testing.T) {
+	shared := &testpb.Response{Resource: &testpb.Resource{Id: "1", Owner: "owner"}}
+	mux := http.NewServeMux()
+	mux.Handle(readMethod, connect.NewUnaryHandler(readMethod, func(context.Context, *connect.Request[testpb.Request]) (*connect.Response[testpb.Response], error) {
+		res := connect.NewResponse(shared)
+		res.Header().Set("x-test", "value")
+		res.Trailer().Set("x-trailer", "value")
+		return res, nil
+	}, connect.WithInterceptors(NewInterceptor(newEngine(t)))))
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	c := connect.NewClient[testpb.Request, testpb.Response](s.Client(), s.URL+readMethod)
+	res, err := c.CallUnary(context.Background(), request(&testpb.Request{}, "reader"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Msg.GetResource().GetOwner() != "" || res.Msg.GetResource().GetId() != "1" {
+		t.Errorf("unexpected masked resource %v", res.Msg.GetResource())
+	}
+	if res.Header().Get("x-test") != "value" || res.Trailer().Get("x-trailer") != "value" {
+		t.Errorf("missing response headers or trailers: %v %v", res.Header(), res.Trailer())
+	}
+	if shared.GetResource().GetOwner() != "owner" {
+		t.Error("the handler response was modified")
+	}
+}
+
+func TestStream(t *testing.T) {
+	c := serve(t, newEngine(t))
+	req := &testpb.Request{Resource: &testpb.Resource{Children: []*testpb.Resource{{Id: "1", Owner: "owner"}, {Id: "2", Owner: "owner"}}}}
+	tests := []struct {
+		role  string
+		code  connect.Code
+		owner string
+	}{
+		{role: "reader"},
+		{role: "admin", owner: "owner"},
+		{role: "writer", code: connect.CodePermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			ss, err := c.watch.CallServerStream(context.Background(), request(req, tt.role))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ss.Close()
+			var got []*testpb.Resource
+			for ss.Receive() {
+				got = append(got, ss.Msg().GetResource())
+			}
+			if connect.CodeOf(ss.Err()) != tt.code && !(ss.Err() == nil && tt.code == 0) {
+				t.Fatalf("got %v, want %v", ss.Err(), tt.code)
+			}
+			if tt.code != 0 {
+				return
+			}
+			if len(got) != 2 {
+				t.Fatalf("expected 2 messages, got %d", len(got))
+			}
+			for _, v := range got {
+				if v.GetOwner() != tt.owner {
+					t.Errorf("%s owner: got %q, want %q", v.GetId(), v.GetOwner(), tt.owner)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DelegationUnaryClientInterceptor returns a client interceptor attaching the principal of the server call
+// the outgoing call is made on behalf of, i.e. the caller roles resolved by the server interceptors,
+// signed with the key and valid for ttl, or DefaultPrincipalTTL if zero. See DelegationRoleFunc.
+// The calls made outside a server call, or by a caller without roles, are sent without principal.
+func DelegationUnaryClientInterceptor(key []byte, ttl time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, err := delegate(ctx, key, ttl, method)
+		if err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// DelegationStreamClientInterceptor is the stream version of DelegationUnaryClientInterceptor
+func DelegationStreamClientInterceptor(key []byte, ttl time.Duration) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, err := delegate(ctx, key, ttl, method)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// delegate returns the outgoing context holding the signed principal of the server call roles, if any
+func delegate(ctx context.Context, key []byte, ttl time.Duration, method string) (context.Context, error) {
+	roles, _ := RolesFromContext(ctx)
+	if len(roles) == 0 {
+		return ctx, nil
+	}
+	if ttl == 0 {
+		ttl = DefaultPrincipalTTL
+	}
+	token, err := SignPrincipal(key, Principal{Roles: ids(roles), Method: method, Expires: time.Now().Add(ttl).Unix()})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "grpc rbac: sign principal: %v", err)
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Set(PrincipalMetadataKey, token)
+	return metadata.NewOutgoingContext(ctx, md), nil
+}
+
+// DelegationRoleFunc returns a RoleFunc for the services called by other services on behalf of users.
+// delegable resolves the roles the calling service may act with on behalf of the users, e.g. from its
+// client certificate. When the call carries a user principal signed with the key, see DelegationUnaryClientInterceptor,
+// the roles are the intersection of the user roles and of the delegable roles: the user roles held by a delegable
+// role, directly or through inheritance, and the delegable roles inherited by a user role. So the calling service
+// cannot be used to do more than the user is allowed to, nor the user to do more than the service may do on its behalf.
+// The calls without principal are resolved with next, e.g. the calling service own roles, or have no roles if nil.
+func DelegationRoleFunc(key []byte, delegable RoleFunc, next RoleFunc) RoleFunc {
+	principal := PrincipalRoleFunc(key)
+	return func(ctx context.Context) ([]Role, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if len(md.Get(PrincipalMetadataKey)) == 0 {
+			if next == nil {
+				return nil, nil
+			}
+			return next(ctx)
+		}
+		users, err := principal(ctx)
+		if err != nil {
+			return nil, err
+		}
+		services, err := delegable(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := FromContext(ctx)
+		if !ok {
+			return nil, fmt.Errorf("%w: missing rbac engine in context", ErrRoleResolution)
+		}
+		return intersect(r, users, services), nil
+	}
+}
+
+// intersect returns the roles granted by both the users and the services roles, considering their inheritance
+func intersect(r RBAC, users, services []Role) []Role {
+	var out []Role
+	seen := make(map[string]struct{})
+	add := func(v Role) {
+		if _, ok := seen[v.ID()]; !ok {
+			seen[v.ID()] = struct{}{}
+			out = append(out, v)
+		}
+	}
+	sids := ids(services)
+	for _, u := range users {
+		if r.HasRole(u.ID(), sids...) {
+			add(u)
+			continue
+		}
+		for _, s := range services {
+			if r.HasRole(s.ID(), u.ID()) {
+				add(s)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,184 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+)
+
+func TestDelegationClientInterceptors(t *testing.T) {
+	r := newTestRBAC(t)
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		roles []string
+	}{
+		{
+			name:  "server call",
+			ctx:   authorize(t, r, readMethod, "reader"),
+			roles: []string{"reader"},
+		},
+		{
+			name: "outside a server call",
+			ctx:  context.Background(),
+		},
+		{
+			name: "caller without roles",
+			ctx:  authorize(t, r, publicMethod),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tokens []string
+			record := func(ctx context.Context) {
+				md, _ := metadata.FromOutgoingContext(ctx)
+				tokens = append(tokens, md.Get(PrincipalMetadataKey)...)
+			}
+			unary := DelegationUnaryClientInterceptor(testKey, time.Minute)
+			if err := unary(tt.ctx, writeMethod, nil, nil, nil, func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				record(ctx)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			stream := DelegationStreamClientInterceptor(testKey, 0)
+			if _, err := stream(tt.ctx, &grpc.StreamDesc{}, nil, watchMethod, func(ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+				record(ctx)
+				return nil, nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.roles == nil {
+				if len(tokens) != 0 {
+					t.Fatalf("expected no principal, got %v", tokens)
+				}
+				return
+			}
+			if len(tokens) != 2 {
+				t.Fatalf("expected 2 principals, got %d", len(tokens))
+			}
+			for i, method := range []string{writeMethod, watchMethod} {
+				p, err := VerifyPrincipal(testKey, tokens[i])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if p.Method != method {
+					t.Errorf("expected principal for %s, got %s", method, p.Method)
+				}
+				if !reflect.DeepEqual(p.Roles, tt.roles) {
+					t.Errorf("expected roles %v, got %v", tt.roles, p.Roles)
+				}
+			}
+		})
+	}
+}
+
+func TestDelegationRoleFunc(t *testing.T) {
+	exp := time.Now().Add(time.Minute).Unix()
+	// the "role" metadata holds the calling service roles
+	tests := []struct {
+		name     string
+		users    []string
+		services []string
+		// noPrincipal sends the call without the user principal
+		noPrincipal bool
+		read        codes.Code
+		write       codes.Code
+	}{
+		{
+			name:     "user role held by the service",
+			users:    []string{"reader"},
+			services: []string{"admin"},
+			read:     codes.OK,
+			write:    codes.PermissionDenied,
+		},
+		{
+			name:     "service role inherited by the user",
+			users:    []string{"admin"},
+			services: []string{"writer"},
+			read:     codes.PermissionDenied,
+			write:    codes.OK,
+		},
+		{
+			name:     "no common role",
+			users:    []string{"reader"},
+			services: []string{"writer"},
+			read:     codes.Unauthenticated,
+			write:    codes.Unauthenticated,
+		},
+		{
+			name:        "service own roles",
+			services:    []string{"writer"},
+			noPrincipal: true,
+			read:        codes.PermissionDenied,
+			write:       codes.OK,
+		},
+	}
+	for _, reauth := range []bool{false, true} {
+		var opts []Option
+		if reauth {
+			opts = append(opts, WithStreamReauthorization(0))
+		}
+		c := serve(t, newTestRBAC(t, append(opts, WithRoleFunc(DelegationRoleFunc(testKey, incomingRoles, incomingRoles)))...))
+		for _, tt := range tests {
+			name := tt.name
+			if reauth {
+				name += " with stream reauthorization"
+			}
+			t.Run(name, func(t *testing.T) {
+				call := func(method string) context.Context {
+					ctx := withRoles(context.Background(), tt.services...)
+					if tt.noPrincipal {
+						return ctx
+					}
+					return withPrincipal(ctx, mustSign(t, testKey, Principal{Roles: tt.users, Method: method, Expires: exp}))
+				}
+				_, err := c.Read(call(readMethod), &testpb.Request{})
+				if got := status.Code(err); got != tt.read {
+					t.Fatalf("Read: expected %v, got %v: %v", tt.read, got, err)
+				}
+				_, err = watch(call(watchMethod), c, &testpb.Request{Resource: &testpb.Resource{Children: []*testpb.Resource{{Id: "1"}}}})
+				if got := status.Code(err); got != tt.read {
+					t.Fatalf("Watch: expected %v, got %v: %v", tt.read, got, err)
+				}
+				_, err = c.Write(call(writeMethod), &testpb.Request{})
+				if got := status.Code(err); got != tt.write {
+					t.Fatalf("Write: expected %v, got %v: %v", tt.write, got, err)
+				}
+				s, err := c.Upload(call(uploadMethod))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := s.Send(&testpb.Request{}); err != nil && tt.write == codes.OK {
+					t.Fatal(err)
+				}
+				_, err = s.CloseAndRecv()
+				if got := status.Code(err); got != tt.write {
+					t.Fatalf("Upload: expected %v, got %v: %v", tt.write, got, err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	pb "go.linka.cloud/grpc-rbac/rbac"
+)
+
+// RegisterDescriptor registers the service methods, and the roles, parents, permissions, public methods
+// and fields rules declared by its rbac options, the same way the generated Register<Service>Permissions
+// function does, without any generated Go code. The package roles defined in the service file are also registered.
+// The roles descriptions found in the options comments are not available at runtime.
+func (r *rbac) RegisterDescriptor(sd protoreflect.ServiceDescriptor) error {
+	d := descriptorRoles{}
+	roles, err := d.fileRoles(sd.ParentFile())
+	if err != nil {
+		return err
+	}
+	errs := loadRoles(r, roles)
+	s, err := d.service(sd)
+	if err != nil {
+		return JoinErrors(append(errs, err)...)
+	}
+	return JoinErrors(append(errs, loadService(r, s)...)...)
+}
+
+// RegisterFromRegistry registers the package roles and the services declaring rbac options of all the files,
+// see RegisterDescriptor. The services without any rbac option are ignored.
+func (r *rbac) RegisterFromRegistry(files *protoregistry.Files) error {
+	d := descriptorRoles{files: files}
+	var errs []error
+	files.RangeFiles(func(f protoreflect.FileDescriptor) bool {
+		roles, err := d.fileRoles(f)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		errs = append(errs, loadRoles(r, roles)...)
+		for i := 0; i < f.Services().Len(); i++ {
+			sd := f.Services().Get(i)
+			if !hasRBACOptions(sd) {
+				continue
+			}
+			s, err := d.service(sd)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			errs = append(errs, loadService(r, s)...)
+		}
+		return true
+	})
+	return JoinErrors(errs...)
+}
+
+func hasRBACOptions(sd protoreflect.ServiceDescriptor) bool {
+	if len(extension(sd.Options(), pb.E_Def).(*pb.RoleDefinition).GetRoles()) != 0 {
+		return true
+	}
+	for i := 0; i < sd.Methods().Len(); i++ {
+		if extension(sd.Methods().Get(i).Options(), pb.E_Access).(*pb.RBAC) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// extension returns the options extension value. The extension is parsed from the unknown fields
+// if the options were decoded without the rbac extensions registered, e.g. for dynamic descriptors.
+func extension(opts protoreflect.ProtoMessage, xt protoreflect.ExtensionType) interface{} {
+	if !proto.HasExtension(opts, xt) && len(opts.ProtoReflect().GetUnknown()) != 0 {
+		if b, err := proto.Marshal(opts); err == nil {
+			m := opts.ProtoReflect().New().Interface()
+			if err := (proto.UnmarshalOptions{Resolver: protoregistry.GlobalTypes}).Unmarshal(b, m); err == nil {
+				opts = m
+			}
+		}
+	}
+	return proto.GetExtension(opts, xt)
+}
+
+// descriptorRoles resolves the roles referenced by the rbac options,
+// the same way protoc-gen-go-rbac does for the package qualified roles ids
+type descriptorRoles struct {
+	// files are used to find the package roles defined in the other files of the package, if set
+	files *protoregistry.Files
+}
+
+type packageRole struct {
+	id   string
+	def  *pb.Role
+	file protoreflect.FileDescriptor
+}
+
+// visit calls fn with the files visible from the file, i.e. the file, its transitive imports
+// and the other files of its package if the files are set
+func (d descriptorRoles) visit(f protoreflect.FileDescriptor, fn func(f protoreflect.FileDescriptor)) {
+	seen := make(map[string]struct{})
+	var walk func(f protoreflect.FileDescriptor)
+	walk = func(f protoreflect.FileDescriptor) {
+		if _, ok := seen[f.Path()]; ok {
+			return
+		}
+		seen[f.Path()] = struct{}{}
+		fn(f)
+		for i := 0; i < f.Imports().Len(); i++ {
+			walk(f.Imports().Get(i).FileDescriptor)
+		}
+	}
+	walk(f)
+	if d.files != nil {
+		d.files.RangeFilesByPackage(f.Package(), func(f protoreflect.FileDescriptor) bool {
+			walk(f)
+			return true
+		})
+	}
+}
+
+// packageRoles returns the package roles visible from the file, i.e. defined in the file package
+// or in the imported files, by "<package>.<name>"
+func (d descriptorRoles) packageRoles(f protoreflect.FileDescriptor) map[string]*packageRole {
+	out := make(map[string]*packageRole)
+	d.visit(f, func(f protoreflect.FileDescriptor) {
+		pkg := string(f.Package())
+		for _, v := range extension(f.Options(), pb.E_FileDef).(*pb.RoleDefinition).GetRoles() {
+			out[pkg+"."+v.GetName()] = &packageRole{id: fmt.Sprintf("%s.%s", pkg, strings.Title(v.GetName())), def: v, file: f}
+		}
+	})
+	return out
+}
+
+// resolve returns the id of the role referenced by name from the file, either a package role,
+// unqualified for the file package or as "<package>.<role>", or a service role as "<Service>.<role>"
+// for the file package services or as "<package>.<Service>.<role>".
+// The referenced service must be visible from the file and declare the role, see hasRole.
+func (d descriptorRoles) resolve(f protoreflect.FileDescriptor, roles map[string]*packageRole, name string) (string, bool) {
+	key := name
+	if !strings.Contains(name, ".") {
+		key = string(f.Package()) + "." + name
+	}
+	if r, ok := roles[key]; ok {
+		return r.id, true
+	}
+	i := strings.LastIndex(name, ".")
+	if i == -1 {
+		return "", false
+	}
+	svc, role := name[:i], name[i+1:]
+	var sd protoreflect.ServiceDescriptor
+	d.visit(f, func(v protoreflect.FileDescriptor) {
+		for j := 0; j < v.Services().Len() && sd == nil; j++ {
+			s := v.Services().Get(j)
+			if string(s.FullName()) == svc || v.Package() == f.Package() && string(s.Name()) == svc {
+				sd = s
+			}
+		}
+	})
+	if sd == nil || !d.hasRole(sd, role) {
+		return "", false
+	}
+	return fmt.Sprintf("%s.%s", sd.FullName(), strings.Title(role)), true
+}
+
+// hasRole reports whether the role is declared by the service, i.e. defined by its rbac.def option
+// or used unqualified in its rbac.access options without being a package role
+func (d descriptorRoles) hasRole(sd protoreflect.ServiceDescriptor, name string) bool {
+	match := func(v string) bool {
+		return v == name || strings.Title(v) == name
+	}
+	for _, v := range extension(sd.Options(), pb.E_Def).(*pb.RoleDefinition).GetRoles() {
+		if match(v.GetName()) {
+			return true
+		}
+	}
+	roles := d.packageRoles(sd.ParentFile())
+	for i := 0; i < sd.Methods().Len(); i++ {
+		for _, v := range extension(sd.Methods().Get(i).Options(), pb.E_Access).(*pb.RBAC).GetRoles() {
+			if _, ok := roles[string(sd.ParentFile().Package())+"."+v]; !ok && !strings.Contains(v, ".") && match(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fileRoles returns the package roles defined in the file
+func (d descriptorRoles) fileRoles(f protoreflect.FileDescriptor) ([]*pb.ManifestRole, error) {
+	defs := extension(f.Options(), pb.E_FileDef).(*pb.RoleDefinition).GetRoles()
+	if len(defs) == 0 {
+		return nil, nil
+	}
+	roles := d.packageRoles(f)
+	var out []*pb.ManifestRole
+	for _, v := range defs {
+		r := manifestRole(fmt.Sprintf("%s.%s", f.Package(), strings.Title(v.GetName())), v)
+		for _, vv := range v.GetParents() {
+			id, ok := d.resolve(f, roles, vv)
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown %s parent role %s", f.Path(), v.GetName(), vv)
+			}
+			r.Parents = append(r.Parents, id)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// service returns the service manifest built from its rbac options
+func (d descriptorRoles) service(sd protoreflect.ServiceDescriptor) (*pb.ManifestService, error) {
+	f := sd.ParentFile()
+	name := string(sd.FullName())
+	roles := d.packageRoles(f)
+	s := &pb.ManifestService{Name: proto.String(name)}
+	local := make(map[string]*pb.ManifestRole)
+	add := func(n string, def *pb.Role) *pb.ManifestRole {
+		r := manifestRole(fmt.Sprintf("%s.%s", name, strings.Title(n)), def)
+		local[n] = r
+		s.Roles = append(s.Roles, r)
+		return r
+	}
+	ref := func(n string) (string, bool) {
+		if r, ok := local[n]; ok {
+			return r.GetId(), true
+		}
+		return d.resolve(f, roles, n)
+	}
+	defs := extension(sd.Options(), pb.E_Def).(*pb.RoleDefinition).GetRoles()
+	for _, v := range defs {
+		add(v.GetName(), v)
+	}
+	for i := 0; i < sd.Methods().Len(); i++ {
+		md := sd.Methods().Get(i)
+		m := &pb.ManifestMethod{Name: proto.String(string(md.Name())), FullMethod: proto.String(fmt.Sprintf("/%s/%s", name, md.Name()))}
+		access := extension(md.Options(), pb.E_Access).(*pb.RBAC)
+		if access.GetPublic() {
+			m.Public = proto.Bool(true)
+		}
+		for _, v := range access.GetRoles() {
+			id, ok := ref(v)
+			if !ok && strings.Contains(v, ".") {
+				return nil, fmt.Errorf("%s: unknown role %s", md.FullName(), v)
+			}
+			if !ok {
+				id = add(v, nil).GetId()
+			}
+			m.Roles = append(m.Roles, id)
+		}
+		s.Methods = append(s.Methods, m)
+	}
+	for _, v := range defs {
+		r := local[v.GetName()]
+		for _, vv := range v.GetParents() {
+			id, ok := ref(vv)
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown %s parent role %s", name, v.GetName(), vv)
+			}
+			r.Parents = append(r.Parents, id)
+		}
+	}
+	msgs, err := d.messages(sd, func(n string) (string, bool) {
+		if id, ok := ref(n); ok || strings.Contains(n, ".") {
+			return id, ok
+		}
+		return fmt.Sprintf("%s.%s", name, strings.Title(n)), true
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.Messages = msgs
+	return s, nil
+}
+
+// messages returns the fields rules of the messages used by the service methods, and of their nested messages
+func (d descriptorRoles) messages(sd protoreflect.ServiceDescriptor, ref func(name string) (string, bool)) ([]*pb.ManifestMessage, error) {
+	var out []*pb.ManifestMessage
+	seen := make(map[protoreflect.FullName]struct{})
+	var walk func(md protoreflect.MessageDescriptor) error
+	walk = func(md protoreflect.MessageDescriptor) error {
+		if _, ok := seen[md.FullName()]; ok {
+			return nil
+		}
+		seen[md.FullName()] = struct{}{}
+		m := &pb.ManifestMessage{Name: proto.String(string(md.FullName()))}
+		for i := 0; i < md.Fields().Len(); i++ {
+			fd := md.Fields().Get(i)
+			if o := extension(fd.Options(), pb.E_Field).(*pb.Field); len(o.GetRoles())+len(o.GetWrite()) != 0 {
+				f := &pb.ManifestField{Name: proto.String(string(fd.Name()))}
+				ids := func(names []string) ([]string, error) {
+					var out []string
+					for _, v := range names {
+						id, ok := ref(v)
+						if !ok {
+							return nil, fmt.Errorf("%s: unknown role %s", fd.FullName(), v)
+						}
+						out = append(out, id)
+					}
+					return out, nil
+				}
+				var err error
+				if f.Read, err = ids(o.GetRoles()); err != nil {
+					return err
+				}
+				if f.Write, err = ids(o.GetWrite()); err != nil {
+					return err
+				}
+				m.Fields = append(m.Fields, f)
+			}
+			if fd.IsMap() {
+				fd = fd.MapValue()
+			}
+			if fd.Message() != nil {
+				if err := walk(fd.Message()); err != nil {
+					return err
+				}
+			}
+		}
+		if len(m.Fields) != 0 {
+			out = append(out, m)
+		}
+		return nil
+	}
+	for i := 0; i < sd.Methods().Len(); i++ {
+		if err := walk(sd.Methods().Get(i).Input()); err != nil {
+			return nil, err
+		}
+		if err := walk(sd.Methods().Get(i).Output()); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func manifestRole(id string, def *pb.Role) *pb.ManifestRole {
+	r := &pb.ManifestRole{Id: proto.String(id)}
+	if def == nil {
+		return r
+	}
+	r.Description, r.DisplayName, r.Deprecated = def.Description, def.DisplayName, def.Deprecated
+	r.Labels = def.GetLabels()
+	return r
+}
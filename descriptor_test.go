@@ -0,0 +1,273 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	_ "google.golang.org/protobuf/types/known/emptypb"
+
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+	pb "go.linka.cloud/grpc-rbac/rbac"
+)
+
+func TestRegisterDescriptor(t *testing.T) {
+	r := New(WithRoleFunc(incomingRoles))
+	if err := r.RegisterDescriptor(testpb.File_internal_testpb_test_proto.Services().ByName("TestService")); err != nil {
+		t.Fatal(err)
+	}
+	checkTestService(t, r)
+}
+
+func TestRegisterFromRegistry(t *testing.T) {
+	files := &protoregistry.Files{}
+	if err := files.RegisterFile(testpb.File_internal_testpb_test_proto); err != nil {
+		t.Fatal(err)
+	}
+	r := New(WithRoleFunc(incomingRoles))
+	if err := r.RegisterFromRegistry(files); err != nil {
+		t.Fatal(err)
+	}
+	checkTestService(t, r)
+}
+
+// checkTestService checks the TestService policy registered from its descriptor
+func checkTestService(t *testing.T, r RBAC) {
+	t.Helper()
+	if info, _ := r.RoleInfo("test.TestService.Admin"); info.DisplayName != "Administrator" || info.Labels["tier"] != "privileged" {
+		t.Errorf("unexpected admin info %v", info)
+	}
+	if info, _ := r.RoleInfo("test.Auditor"); info.Description != "read the resources for audit purposes" {
+		t.Errorf("unexpected auditor info %v", info)
+	}
+	c := serve(t, r)
+	req := &testpb.Request{Resource: &testpb.Resource{Owner: "owner"}}
+	tests := []struct {
+		role  string
+		read  codes.Code
+		write codes.Code
+		owner string
+	}{
+		{role: "test.TestService.Reader", write: codes.PermissionDenied},
+		{role: "test.TestService.Writer", read: codes.PermissionDenied},
+		{role: "test.Auditor", write: codes.PermissionDenied},
+		{role: "test.TestService.Admin", owner: "owner"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			ctx := withRoles(context.Background(), tt.role)
+			res, err := c.Read(ctx, req)
+			if status.Code(err) != tt.read {
+				t.Fatalf("read: got %v, want %v", err, tt.read)
+			}
+			if err == nil && res.GetResource().GetOwner() != tt.owner {
+				t.Errorf("read owner: got %q, want %q", res.GetResource().GetOwner(), tt.owner)
+			}
+			if _, err := c.Write(ctx, req); status.Code(err) != tt.write {
+				t.Errorf("write: got %v, want %v", err, tt.write)
+			}
+		})
+	}
+	if _, err := c.Public(context.Background(), req); err != nil {
+		t.Errorf("public: %v", err)
+	}
+}
+
+// dynamicFile returns a file of the dyn package, with the Other service defining the admin role,
+// the viewer package role inheriting the parents, and the Svc service Get method granted to the roles
+func dynamicFile(t *testing.T, roles, parents []string) protoreflect.FileDescriptor {
+	t.Helper()
+	method := func(roles ...string) *descriptorpb.MethodDescriptorProto {
+		o := &descriptorpb.MethodOptions{}
+		proto.SetExtension(o, pb.E_Access, &pb.RBAC{Roles: roles})
+		return &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String("Get"),
+			InputType:  proto.String(".google.protobuf.Empty"),
+			OutputType: proto.String(".google.protobuf.Empty"),
+			Options:    o,
+		}
+	}
+	fo := &descriptorpb.FileOptions{}
+	proto.SetExtension(fo, pb.E_FileDef, &pb.RoleDefinition{Roles: []*pb.Role{{Name: proto.String("viewer"), Parents: parents}}})
+	so := &descriptorpb.ServiceOptions{}
+	proto.SetExtension(so, pb.E_Def, &pb.RoleDefinition{Roles: []*pb.Role{{Name: proto.String("admin")}}})
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("dyn.proto"),
+		Package:    proto.String("dyn"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"rbac/rbac.proto", "google/protobuf/empty.proto"},
+		Options:    fo,
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{Name: proto.String("Other"), Options: so, Method: []*descriptorpb.MethodDescriptorProto{method("admin", "reader")}},
+			{Name: proto.String("Svc"), Method: []*descriptorpb.MethodDescriptorProto{method(roles...)}},
+		},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd
+}
+
+func TestRegisterDescriptorRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		roles   []string
+		parents []string
+		want    []string
+		err     string
+	}{
+		{name: "local", roles: []string{"user"}, want: []string{"dyn.Svc.User"}},
+		{name: "package", roles: []string{"viewer", "dyn.viewer"}, want: []string{"dyn.Viewer", "dyn.Viewer"}},
+		{name: "service", roles: []string{"Other.admin", "Other.Admin", "Other.reader"}, want: []string{"dyn.Other.Admin", "dyn.Other.Admin", "dyn.Other.Reader"}},
+		{name: "qualified service", roles: []string{"dyn.Other.admin"}, want: []string{"dyn.Other.Admin"}},
+		{name: "imported", roles: []string{"test.TestService.reader", "test.auditor"}, want: []string{"test.TestService.Reader", "test.Auditor"}},
+		{name: "parents", roles: []string{"viewer"}, parents: []string{"Other.admin", "dyn.Other.reader"}, want: []string{"dyn.Viewer"}},
+		{name: "unknown service role", roles: []string{"Other.amdin"}, err: "dyn.Svc.Get: unknown role Other.amdin"},
+		{name: "unknown service", roles: []string{"other.Missing.role"}, err: "dyn.Svc.Get: unknown role other.Missing.role"},
+		{name: "not imported", roles: []string{"test.TestService.reader"}, err: "dyn.Svc.Get: unknown role test.TestService.reader"},
+		{name: "unknown parent", roles: []string{"viewer"}, parents: []string{"Other.amdin"}, err: "dyn.proto: unknown viewer parent role Other.amdin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fd := dynamicFile(t, tt.roles, tt.parents)
+			if tt.name == "imported" {
+				fd = importing(t, fd)
+			}
+			r := New()
+			err := r.RegisterDescriptor(fd.Services().ByName("Svc"))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			perm := NewGRPCPermission("dyn.Svc", "Get")
+			for _, v := range tt.want {
+				if !r.IsGranted(v, perm, nil) {
+					t.Errorf("%s is not granted %s", v, perm.ID())
+				}
+			}
+		})
+	}
+}
+
+// fieldFile returns the dyn.proto file where the secret field of the Svc.Get messages is restricted to the roles,
+// the viewer package role and the Other.admin service role being defined
+func fieldFile(t *testing.T, roles []string) protoreflect.FileDescriptor {
+	t.Helper()
+	fo := &descriptorpb.FileOptions{}
+	proto.SetExtension(fo, pb.E_FileDef, &pb.RoleDefinition{Roles: []*pb.Role{{Name: proto.String("viewer")}}})
+	so := &descriptorpb.ServiceOptions{}
+	proto.SetExtension(so, pb.E_Def, &pb.RoleDefinition{Roles: []*pb.Role{{Name: proto.String("admin")}}})
+	ffo := &descriptorpb.FieldOptions{}
+	proto.SetExtension(ffo, pb.E_Field, &pb.Field{Roles: roles})
+	mo := &descriptorpb.MethodOptions{}
+	proto.SetExtension(mo, pb.E_Access, &pb.RBAC{Roles: []string{"user"}})
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("dyn.proto"),
+		Package:    proto.String("dyn"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"rbac/rbac.proto"},
+		Options:    fo,
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Item"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("secret"),
+				JsonName: proto.String("secret"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				Options:  ffo,
+			}},
+		}},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{Name: proto.String("Other"), Options: so},
+			{Name: proto.String("Svc"), Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("Get"),
+				InputType:  proto.String(".dyn.Item"),
+				OutputType: proto.String(".dyn.Item"),
+				Options:    mo,
+			}}},
+		},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd
+}
+
+func TestRegisterDescriptorFieldRoles(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []string
+		want  []string
+		err   string
+	}{
+		{name: "local", roles: []string{"user", "other"}, want: []string{"dyn.Svc.User", "dyn.Svc.Other"}},
+		{name: "package", roles: []string{"viewer", "dyn.viewer"}, want: []string{"dyn.Viewer", "dyn.Viewer"}},
+		{name: "service", roles: []string{"Other.admin", "dyn.Other.admin"}, want: []string{"dyn.Other.Admin", "dyn.Other.Admin"}},
+		{name: "unknown service role", roles: []string{"Other.amdin"}, err: "dyn.Item.secret: unknown role Other.amdin"},
+		{name: "unknown service", roles: []string{"other.Missing.role"}, err: "dyn.Item.secret: unknown role other.Missing.role"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sd := fieldFile(t, tt.roles).Services().ByName("Svc")
+			s, err := descriptorRoles{}.service(sd)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got %v, want %q", err, tt.err)
+				}
+				if err := New().RegisterDescriptor(sd); err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("register: got %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(s.GetMessages()) != 1 || len(s.GetMessages()[0].GetFields()) != 1 {
+				t.Fatalf("unexpected messages %v", s.GetMessages())
+			}
+			if got := s.GetMessages()[0].GetFields()[0].GetRead(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// importing returns the file with the test proto file added to its imports
+func importing(t *testing.T, fd protoreflect.FileDescriptor) protoreflect.FileDescriptor {
+	t.Helper()
+	fdp := protodesc.ToFileDescriptorProto(fd)
+	fdp.Dependency = append(fdp.Dependency, testpb.File_internal_testpb_test_proto.Path())
+	out, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
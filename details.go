@@ -0,0 +1,190 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+)
+
+// ErrorDetailsLevel is the level of details of the authorization denials errors, see WithErrorDetails
+type ErrorDetailsLevel int
+
+const (
+	// ErrorDetailsNone returns the denials as a plain "permission denied" PermissionDenied status,
+	// without any role nor details, for untrusted callers
+	ErrorDetailsNone ErrorDetailsLevel = iota
+	// ErrorDetailsReason adds a google.rpc.ErrorInfo with the denial reason and the denied method or field,
+	// and a google.rpc.PreconditionFailure for the denied fields. The message contains the caller roles.
+	// It is the default level.
+	ErrorDetailsReason
+	// ErrorDetailsFull also adds the caller roles and the roles allowed to call the method or set the field
+	// to the google.rpc.ErrorInfo metadata
+	ErrorDetailsFull
+)
+
+const (
+	// ErrorDomain is the domain of the denials google.rpc.ErrorInfo
+	ErrorDomain = "grpc-rbac.linka.cloud"
+
+	// ReasonMethodDenied is the google.rpc.ErrorInfo reason of a method call denial
+	ReasonMethodDenied = "METHOD_DENIED"
+	// ReasonFieldDenied is the google.rpc.ErrorInfo reason of a protected field write denial
+	ReasonFieldDenied = "FIELD_DENIED"
+
+	// ViolationFieldWrite is the google.rpc.PreconditionFailure violation type of a protected field write denial
+	ViolationFieldWrite = "FIELD_WRITE"
+)
+
+// google.rpc.ErrorInfo metadata keys
+const (
+	metadataMethod        = "method"
+	metadataField         = "field"
+	metadataRoles         = "roles"
+	metadataRequiredRoles = "required_roles"
+)
+
+// Denial is an authorization denial parsed from an error details
+type Denial struct {
+	// Reason is the denial reason, e.g. ReasonMethodDenied
+	Reason string
+	// Method is the denied method or permission
+	Method string
+	// Field is the denied field path, for ReasonFieldDenied
+	Field string
+	// Roles are the caller roles, only with ErrorDetailsFull
+	Roles []string
+	// RequiredRoles are the roles allowed to call the method or set the field, only with ErrorDetailsFull
+	RequiredRoles []string
+}
+
+// DenialFromError returns the authorization denial carried by the error status details, if any
+func DenialFromError(err error) (*Denial, bool) {
+	s, ok := status.FromError(err)
+	if !ok || s.Code() != codes.PermissionDenied {
+		return nil, false
+	}
+	var d *Denial
+	for _, v := range s.Details() {
+		switch v := v.(type) {
+		case *errdetails.ErrorInfo:
+			if v.GetDomain() != ErrorDomain {
+				continue
+			}
+			if d == nil {
+				d = &Denial{}
+			}
+			d.Reason = v.GetReason()
+			d.Method = v.GetMetadata()[metadataMethod]
+			d.Field = v.GetMetadata()[metadataField]
+			d.Roles = split(v.GetMetadata()[metadataRoles])
+			d.RequiredRoles = split(v.GetMetadata()[metadataRequiredRoles])
+		case *errdetails.PreconditionFailure:
+			for _, vv := range v.GetViolations() {
+				if vv.GetType() != ViolationFieldWrite {
+					continue
+				}
+				if d == nil {
+					d = &Denial{Reason: ReasonFieldDenied}
+				}
+				d.Field = vv.GetSubject()
+			}
+		}
+	}
+	return d, d != nil
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// methodDenied returns the PermissionDenied error of the roles not granted the method permission, wrapping ErrPermissionDenied
+func (r *rbac) methodDenied(roles []Role, perm Permission) error {
+	ids := ids(roles)
+	if r.errDetails == ErrorDetailsNone {
+		return &statusError{s: status.New(codes.PermissionDenied, "permission denied"), err: ErrPermissionDenied}
+	}
+	info := &errdetails.ErrorInfo{
+		Reason:   ReasonMethodDenied,
+		Domain:   ErrorDomain,
+		Metadata: map[string]string{metadataMethod: perm.ID()},
+	}
+	if r.errDetails == ErrorDetailsFull {
+		info.Metadata[metadataRoles] = strings.Join(ids, ",")
+		info.Metadata[metadataRequiredRoles] = strings.Join(r.granted(perm), ",")
+	}
+	s := status.New(codes.PermissionDenied, fmt.Sprintf("[%s]: not allowed to call %s", strings.Join(ids, ", "), perm.ID()))
+	return &statusError{s: withDetails(s, info), err: ErrPermissionDenied}
+}
+
+// fieldDenied returns the PermissionDenied error of the roles not allowed to set the field, wrapping ErrPermissionDenied
+func (r *rbac) fieldDenied(roles []Role, path string, required []string) error {
+	ids := ids(roles)
+	if r.errDetails == ErrorDetailsNone {
+		return &statusError{s: status.New(codes.PermissionDenied, "permission denied"), err: ErrPermissionDenied}
+	}
+	info := &errdetails.ErrorInfo{
+		Reason:   ReasonFieldDenied,
+		Domain:   ErrorDomain,
+		Metadata: map[string]string{metadataField: path},
+	}
+	violation := &errdetails.PreconditionFailure_Violation{
+		Type:        ViolationFieldWrite,
+		Subject:     path,
+		Description: "the caller roles are not allowed to set the field",
+	}
+	if r.errDetails == ErrorDetailsFull {
+		info.Metadata[metadataRoles] = strings.Join(ids, ",")
+		info.Metadata[metadataRequiredRoles] = strings.Join(required, ",")
+		violation.Description = fmt.Sprintf("only [%s] are allowed to set the field", strings.Join(required, ", "))
+	}
+	s := status.New(codes.PermissionDenied, fmt.Sprintf("[%s]: not allowed to set %s", strings.Join(ids, ", "), path))
+	return &statusError{s: withDetails(s, info, &errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{violation}}), err: ErrPermissionDenied}
+}
+
+// granted returns the ids of the roles granted the permission, directly or through their parents
+func (r *rbac) granted(perm Permission) []string {
+	// Walk holds the engine lock, so the roles are checked once collected
+	var ids []string
+	_ = r.Walk(func(role Role, _ []string) error {
+		ids = append(ids, role.ID())
+		return nil
+	})
+	var out []string
+	for _, v := range ids {
+		if r.rbac.IsGranted(v, perm, nil) {
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// withDetails returns the status with the details, or without them if they cannot be added
+func withDetails(s *status.Status, details ...protoiface.MessageV1) *status.Status {
+	if v, err := s.WithDetails(details...); err == nil {
+		return v
+	}
+	return s
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+)
+
+func TestErrorDetails(t *testing.T) {
+	tests := []struct {
+		name   string
+		level  ErrorDetailsLevel
+		method *Denial
+		field  *Denial
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:   "reason",
+			level:  ErrorDetailsReason,
+			method: &Denial{Reason: ReasonMethodDenied, Method: writeMethod},
+			field:  &Denial{Reason: ReasonFieldDenied, Field: "resource.status"},
+		},
+		{
+			name:  "full",
+			level: ErrorDetailsFull,
+			method: &Denial{
+				Reason:        ReasonMethodDenied,
+				Method:        writeMethod,
+				Roles:         []string{"reader"},
+				RequiredRoles: []string{"admin", "writer"},
+			},
+			field: &Denial{
+				Reason:        ReasonFieldDenied,
+				Field:         "resource.status",
+				Roles:         []string{"writer"},
+				RequiredRoles: []string{"admin"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := serve(t, newTestRBAC(t, WithErrorDetails(tt.level)))
+			_, err := c.Write(withRoles(context.Background(), "reader"), &testpb.Request{})
+			check(t, err, tt.method)
+			_, err = c.Write(withRoles(context.Background(), "writer"), &testpb.Request{Resource: &testpb.Resource{Status: testpb.Resource_ACTIVE}})
+			check(t, err, tt.field)
+		})
+	}
+}
+
+// check fails the test if the error is not a PermissionDenied error carrying the denial
+func check(t *testing.T, err error, want *Denial) {
+	t.Helper()
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("got %v, want PermissionDenied", err)
+	}
+	got, ok := DenialFromError(err)
+	if want == nil {
+		if ok {
+			t.Errorf("unexpected denial details %+v", got)
+		}
+		if s, _ := status.FromError(err); s.Message() != "permission denied" {
+			t.Errorf("unexpected message %q", s.Message())
+		}
+		return
+	}
+	if !ok {
+		t.Fatalf("missing denial details in %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDenialFromError(t *testing.T) {
+	for _, err := range []error{
+		nil,
+		errors.New("error"),
+		status.Error(codes.PermissionDenied, "permission denied"),
+		status.Error(codes.Unauthenticated, "unauthenticated"),
+	} {
+		if d, ok := DenialFromError(err); ok {
+			t.Errorf("%v: unexpected denial %+v", err, d)
+		}
+	}
+}
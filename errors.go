@@ -0,0 +1,146 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrUnauthenticated is the error of a missing caller identity, i.e. no roles.
+	// A RoleFunc should return it (or wrap it) when the caller credentials are missing or invalid.
+	ErrUnauthenticated = errors.New("grpc rbac: unauthenticated")
+	// ErrRoleResolution is the error of a RoleFunc failing to resolve the roles, e.g. the identity store is not reachable.
+	// The RoleFunc errors which are neither gRPC status errors nor wrapping one of these errors are wrapped with it.
+	ErrRoleResolution = errors.New("grpc rbac: roles resolution failed")
+	// ErrPermissionDenied is the error of a policy denial
+	ErrPermissionDenied = errors.New("grpc rbac: permission denied")
+	// ErrMissingRoleFunc is the error of UnimplementedRoleFunc, the default RoleFunc
+	ErrMissingRoleFunc = errors.New("grpc rbac: missing role function")
+)
+
+// ErrorMapper converts the role resolution and authorization errors to the errors returned to the callers,
+// see WithErrorMapper
+type ErrorMapper func(err error) error
+
+// DefaultErrorMapper converts the errors to gRPC status errors still wrapping them:
+// ErrUnauthenticated to Unauthenticated, ErrPermissionDenied to PermissionDenied, ErrMissingRoleFunc to Internal
+// and ErrRoleResolution to Unavailable. The gRPC status errors, e.g. returned by the RoleFunc, are returned as is.
+func DefaultErrorMapper(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var code codes.Code
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		code = codes.Unauthenticated
+	case errors.Is(err, ErrPermissionDenied):
+		code = codes.PermissionDenied
+	case errors.Is(err, ErrMissingRoleFunc):
+		code = codes.Internal
+	default:
+		code = codes.Unavailable
+	}
+	return &statusError{s: status.New(code, err.Error()), err: err}
+}
+
+// statusError is a gRPC status error wrapping the error it was created from
+type statusError struct {
+	s   *status.Status
+	err error
+}
+
+func (e *statusError) Error() string {
+	return e.s.Err().Error()
+}
+
+func (e *statusError) GRPCStatus() *status.Status {
+	return e.s
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+// resolutionError wraps the RoleFunc errors not defined by the package
+type resolutionError struct {
+	err error
+}
+
+func (e resolutionError) Error() string {
+	return ErrRoleResolution.Error() + ": " + e.err.Error()
+}
+
+func (e resolutionError) Unwrap() error {
+	return e.err
+}
+
+func (e resolutionError) Is(target error) bool {
+	return target == ErrRoleResolution
+}
+
+// resolutionErr classifies the RoleFunc error
+func resolutionErr(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	for _, v := range []error{ErrUnauthenticated, ErrRoleResolution, ErrPermissionDenied, ErrMissingRoleFunc} {
+		if errors.Is(err, v) {
+			return err
+		}
+	}
+	return resolutionError{err: err}
+}
+
+// mapError converts the error with the configured ErrorMapper
+func (r *rbac) mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return r.errMapper(err)
+}
+
+// Errors aggregates multiple errors
+type Errors []error
+
+func (e Errors) Error() string {
+	var parts []string
+	for _, v := range e {
+		parts = append(parts, v.Error())
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// JoinErrors returns the non-nil errs as Errors, or nil if there is none
+func JoinErrors(errs ...error) error {
+	var out Errors
+	for _, v := range errs {
+		if v != nil {
+			out = append(out, v)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,148 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+)
+
+func TestErrorCodes(t *testing.T) {
+	roleFunc := func(err error) Option {
+		return WithRoleFunc(func(ctx context.Context) ([]Role, error) {
+			return nil, err
+		})
+	}
+	tests := []struct {
+		name     string
+		opts     []Option
+		roles    []string
+		req      *testpb.Request
+		want     codes.Code
+		reason   string
+		violates string
+	}{
+		{name: "unauthenticated", opts: []Option{roleFunc(ErrUnauthenticated)}, want: codes.Unauthenticated},
+		{name: "wrapped unauthenticated", opts: []Option{roleFunc(fmt.Errorf("invalid token: %w", ErrUnauthenticated))}, want: codes.Unauthenticated},
+		{name: "no roles", want: codes.Unauthenticated},
+		{name: "role resolution", opts: []Option{roleFunc(errors.New("store unreachable"))}, want: codes.Unavailable},
+		{name: "wrapped role resolution", opts: []Option{roleFunc(fmt.Errorf("store: %w", ErrRoleResolution))}, want: codes.Unavailable},
+		{name: "missing role func", opts: []Option{WithRoleFunc(UnimplementedRoleFunc)}, want: codes.Internal},
+		{name: "status", opts: []Option{roleFunc(status.Error(codes.Aborted, "aborted"))}, want: codes.Aborted},
+		{name: "method denied", roles: []string{"reader"}, want: codes.PermissionDenied, reason: ReasonMethodDenied},
+		{
+			name:     "field denied",
+			roles:    []string{"writer"},
+			req:      &testpb.Request{Resource: &testpb.Resource{Status: testpb.Resource_ACTIVE}},
+			want:     codes.PermissionDenied,
+			reason:   ReasonFieldDenied,
+			violates: "resource.status",
+		},
+		{name: "allowed", roles: []string{"writer"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := serve(t, newTestRBAC(t, tt.opts...))
+			req := tt.req
+			if req == nil {
+				req = &testpb.Request{}
+			}
+			_, err := c.Write(withRoles(context.Background(), tt.roles...), req)
+			s, _ := status.FromError(err)
+			if s.Code() != tt.want {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			var info *errdetails.ErrorInfo
+			var violation *errdetails.PreconditionFailure_Violation
+			for _, v := range s.Details() {
+				switch v := v.(type) {
+				case *errdetails.ErrorInfo:
+					info = v
+				case *errdetails.PreconditionFailure:
+					violation = v.GetViolations()[0]
+				}
+			}
+			if tt.reason == "" {
+				if info != nil {
+					t.Errorf("unexpected error info %v", info)
+				}
+			} else if info.GetDomain() != ErrorDomain || info.GetReason() != tt.reason {
+				t.Errorf("got error info %v, want reason %s", info, tt.reason)
+			}
+			if tt.violates == "" {
+				if violation != nil {
+					t.Errorf("unexpected violation %v", violation)
+				}
+			} else if violation.GetType() != ViolationFieldWrite || violation.GetSubject() != tt.violates {
+				t.Errorf("got violation %v, want %s", violation, tt.violates)
+			}
+		})
+	}
+}
+
+func TestDefaultErrorMapper(t *testing.T) {
+	st := status.Error(codes.Aborted, "aborted")
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+		is   error
+	}{
+		{name: "unauthenticated", err: fmt.Errorf("token: %w", ErrUnauthenticated), want: codes.Unauthenticated, is: ErrUnauthenticated},
+		{name: "permission denied", err: ErrPermissionDenied, want: codes.PermissionDenied, is: ErrPermissionDenied},
+		{name: "missing role func", err: ErrMissingRoleFunc, want: codes.Internal, is: ErrMissingRoleFunc},
+		{name: "role resolution", err: resolutionErr(errors.New("store unreachable")), want: codes.Unavailable, is: ErrRoleResolution},
+		{name: "status", err: st, want: codes.Aborted, is: st},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DefaultErrorMapper(tt.err)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if !errors.Is(err, tt.is) {
+				t.Errorf("%v does not wrap %v", err, tt.is)
+			}
+		})
+	}
+}
+
+func TestWithErrorMapper(t *testing.T) {
+	var mapped []error
+	c := serve(t, newTestRBAC(t, WithErrorMapper(func(err error) error {
+		mapped = append(mapped, err)
+		if errors.Is(err, ErrPermissionDenied) {
+			return status.Error(codes.NotFound, "not found")
+		}
+		return DefaultErrorMapper(err)
+	})))
+	if _, err := c.Write(withRoles(context.Background(), "reader"), &testpb.Request{}); status.Code(err) != codes.NotFound {
+		t.Errorf("got %v, want NotFound", err)
+	}
+	if _, err := c.Write(context.Background(), &testpb.Request{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("got %v, want Unauthenticated", err)
+	}
+	if len(mapped) != 2 || !errors.Is(mapped[0], ErrPermissionDenied) || !errors.Is(mapped[1], ErrUnauthenticated) {
+		t.Errorf("unexpected mapped errors %v", mapped)
+	}
+}
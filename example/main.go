@@ -60,7 +60,9 @@ func main() {
 	svc := NewResourceService()
 
 	// register the service permissions
-	example.RegisterResourceServicePermissions(rbac)
+	if err := example.RegisterResourceServicePermissions(rbac); err != nil {
+		log.Fatal(err)
+	}
 
 	// create the in-process grpc channel
 	channel := (&inprocgrpc.Channel{}).
@@ -82,10 +84,36 @@ func main() {
 		log.Fatal("reader should not be able to create")
 	}
 
-	if _, err := client.Create(rbacCtx(ctx, example.ResourceServiceRoles.Writer), &example.CreateRequest{Payload: &example.Resource{Id: "0"}}); err != nil {
+	if _, err := client.Create(rbacCtx(ctx, example.ResourceServiceRoles.Writer), &example.CreateRequest{Payload: &example.Resource{Id: "0", Owner: "me"}}); err != nil {
 		log.Fatal(err)
 	}
 
+	// the status field can only be set by the admin role
+	if _, err := client.Create(rbacCtx(ctx, example.ResourceServiceRoles.Writer), &example.CreateRequest{Payload: &example.Resource{Id: "0", Status: example.Resource_ACTIVE}}); err == nil {
+		log.Fatal("writer should not be able to set the status")
+	}
+
+	if _, err := client.Update(rbacCtx(ctx, example.ResourceServiceRoles.Admin), &example.UpdateRequest{Payload: &example.Resource{Id: "0", Owner: "me", Status: example.Resource_ACTIVE}}); err != nil {
+		log.Fatal(err)
+	}
+
+	// the owner field is restricted to the admin role
+	res, err := client.Read(rbacCtx(ctx, example.ResourceServiceRoles.Reader), &example.ReadRequest{Id: "0"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if res.GetResult().GetOwner() != "" {
+		log.Fatal("reader should not be able to see the owner")
+	}
+
+	res, err = client.Read(rbacCtx(ctx, example.ResourceServiceRoles.Admin), &example.ReadRequest{Id: "0"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if res.GetResult().GetOwner() != "me" {
+		log.Fatal("admin should be able to see the owner")
+	}
+
 	ss, err := client.Watch(rbacCtx(ctx, example.ResourceServiceRoles.Writer), &example.WatchRequest{})
 	if err != nil {
 		log.Fatal(err)
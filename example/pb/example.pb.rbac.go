@@ -16,6 +16,8 @@
 package example
 
 import (
+	"context"
+
 	grpc_rbac "go.linka.cloud/grpc-rbac"
 )
 
@@ -35,74 +37,182 @@ var ResourceServicePermissions = struct {
 	Watch:  grpc_rbac.NewGRPCPermission("example.ResourceService", "Watch"),
 }
 
-var ResourceServiceRoles = struct {
-	Admin   *grpc_rbac.StdRole
-	Reader  *grpc_rbac.StdRole
-	Watcher *grpc_rbac.StdRole
-	Writer  *grpc_rbac.StdRole
-}{
-	Admin:   grpc_rbac.NewStdRole("ResourceService.Admin"),
-	Reader:  grpc_rbac.NewStdRole("ResourceService.Reader"),
-	Watcher: grpc_rbac.NewStdRole("ResourceService.Watcher"),
-	Writer:  grpc_rbac.NewStdRole("ResourceService.Writer"),
+// ResourceServiceMethod is a ResourceService method full name
+type ResourceServiceMethod string
+
+const (
+	ResourceServiceMethodCreate ResourceServiceMethod = "/example.ResourceService/Create"
+	ResourceServiceMethodRead   ResourceServiceMethod = "/example.ResourceService/Read"
+	ResourceServiceMethodUpdate ResourceServiceMethod = "/example.ResourceService/Update"
+	ResourceServiceMethodDelete ResourceServiceMethod = "/example.ResourceService/Delete"
+	ResourceServiceMethodList   ResourceServiceMethod = "/example.ResourceService/List"
+	ResourceServiceMethodWatch  ResourceServiceMethod = "/example.ResourceService/Watch"
+)
+
+// ResourceServiceAuthz checks the ResourceService methods access of the caller roles stored in the context by the server interceptors
+var ResourceServiceAuthz = resourceServiceAuthz{}
+
+type resourceServiceAuthz struct{}
+
+// CanCreate reports whether the caller is allowed to call Create
+func (resourceServiceAuthz) CanCreate(ctx context.Context) bool {
+	return grpc_rbac.Can(ctx, ResourceServicePermissions.Create)
 }
 
-func RegisterResourceServicePermissions(rbac grpc_rbac.RBAC) { // Assign Admin permissions
-	if err := ResourceServiceRoles.Admin.Assign(ResourceServicePermissions.Create); err != nil {
-		panic(err)
-	}
-	// Register Admin role
-	if err := rbac.Add(ResourceServiceRoles.Admin); err != nil {
-		panic(err)
-	}
+// RequireCreate returns a PermissionDenied error if the caller is not allowed to call Create
+func (resourceServiceAuthz) RequireCreate(ctx context.Context) error {
+	return grpc_rbac.Require(ctx, ResourceServicePermissions.Create)
+}
 
-	// Assign Reader permissions
-	if err := ResourceServiceRoles.Reader.Assign(ResourceServicePermissions.Read); err != nil {
-		panic(err)
+// CanRead reports whether the caller is allowed to call Read
+func (resourceServiceAuthz) CanRead(ctx context.Context) bool {
+	return grpc_rbac.Can(ctx, ResourceServicePermissions.Read)
+}
+
+// RequireRead returns a PermissionDenied error if the caller is not allowed to call Read
+func (resourceServiceAuthz) RequireRead(ctx context.Context) error {
+	return grpc_rbac.Require(ctx, ResourceServicePermissions.Read)
+}
+
+// CanUpdate reports whether the caller is allowed to call Update
+func (resourceServiceAuthz) CanUpdate(ctx context.Context) bool {
+	return grpc_rbac.Can(ctx, ResourceServicePermissions.Update)
+}
+
+// RequireUpdate returns a PermissionDenied error if the caller is not allowed to call Update
+func (resourceServiceAuthz) RequireUpdate(ctx context.Context) error {
+	return grpc_rbac.Require(ctx, ResourceServicePermissions.Update)
+}
+
+// CanDelete reports whether the caller is allowed to call Delete
+func (resourceServiceAuthz) CanDelete(ctx context.Context) bool {
+	return grpc_rbac.Can(ctx, ResourceServicePermissions.Delete)
+}
+
+// RequireDelete returns a PermissionDenied error if the caller is not allowed to call Delete
+func (resourceServiceAuthz) RequireDelete(ctx context.Context) error {
+	return grpc_rbac.Require(ctx, ResourceServicePermissions.Delete)
+}
+
+// CanList reports whether the caller is allowed to call List
+func (resourceServiceAuthz) CanList(ctx context.Context) bool {
+	return grpc_rbac.Can(ctx, ResourceServicePermissions.List)
+}
+
+// RequireList returns a PermissionDenied error if the caller is not allowed to call List
+func (resourceServiceAuthz) RequireList(ctx context.Context) error {
+	return grpc_rbac.Require(ctx, ResourceServicePermissions.List)
+}
+
+// CanWatch reports whether the caller is allowed to call Watch
+func (resourceServiceAuthz) CanWatch(ctx context.Context) bool {
+	return grpc_rbac.Can(ctx, ResourceServicePermissions.Watch)
+}
+
+// RequireWatch returns a PermissionDenied error if the caller is not allowed to call Watch
+func (resourceServiceAuthz) RequireWatch(ctx context.Context) error {
+	return grpc_rbac.Require(ctx, ResourceServicePermissions.Watch)
+}
+
+// AllowedMethods returns the ResourceService methods the caller is allowed to call
+func (resourceServiceAuthz) AllowedMethods(ctx context.Context) []ResourceServiceMethod {
+	var out []ResourceServiceMethod
+	if grpc_rbac.Can(ctx, ResourceServicePermissions.Create) {
+		out = append(out, ResourceServiceMethodCreate)
 	}
-	if err := ResourceServiceRoles.Reader.Assign(ResourceServicePermissions.List); err != nil {
-		panic(err)
+	if grpc_rbac.Can(ctx, ResourceServicePermissions.Read) {
+		out = append(out, ResourceServiceMethodRead)
 	}
-	// Register Reader role
-	if err := rbac.Add(ResourceServiceRoles.Reader); err != nil {
-		panic(err)
+	if grpc_rbac.Can(ctx, ResourceServicePermissions.Update) {
+		out = append(out, ResourceServiceMethodUpdate)
 	}
-
-	// Assign Watcher permissions
-	if err := ResourceServiceRoles.Watcher.Assign(ResourceServicePermissions.Watch); err != nil {
-		panic(err)
+	if grpc_rbac.Can(ctx, ResourceServicePermissions.Delete) {
+		out = append(out, ResourceServiceMethodDelete)
 	}
-	// Register Watcher role
-	if err := rbac.Add(ResourceServiceRoles.Watcher); err != nil {
-		panic(err)
+	if grpc_rbac.Can(ctx, ResourceServicePermissions.List) {
+		out = append(out, ResourceServiceMethodList)
+	}
+	if grpc_rbac.Can(ctx, ResourceServicePermissions.Watch) {
+		out = append(out, ResourceServiceMethodWatch)
 	}
+	return out
+}
 
-	// Assign Writer permissions
-	if err := ResourceServiceRoles.Writer.Assign(ResourceServicePermissions.Create); err != nil {
-		panic(err)
+var ResourceServiceRoles = struct {
+	// Full access to the resources, including the owner and status fields.
+	Admin *grpc_rbac.DescribedRole
+	// read and list the resources
+	Reader *grpc_rbac.DescribedRole
+	// watch the resources events
+	Watcher *grpc_rbac.DescribedRole
+	// create, update and delete the resources
+	Writer *grpc_rbac.DescribedRole
+}{
+	Admin:   grpc_rbac.NewDescribedRole(grpc_rbac.RoleInfo{ID: "example.ResourceService.Admin", DisplayName: "Administrator", Description: "Full access to the resources, including the owner and status fields.", Labels: map[string]string{"tier": "privileged"}}),
+	Reader:  grpc_rbac.NewDescribedRole(grpc_rbac.RoleInfo{ID: "example.ResourceService.Reader", Description: "read and list the resources"}),
+	Watcher: grpc_rbac.NewDescribedRole(grpc_rbac.RoleInfo{ID: "example.ResourceService.Watcher", Description: "watch the resources events"}),
+	Writer:  grpc_rbac.NewDescribedRole(grpc_rbac.RoleInfo{ID: "example.ResourceService.Writer", Description: "create, update and delete the resources"}),
+}
+
+// RegisterResourceServicePermissions registers the ResourceService roles, permissions and rules to the rbac engine.
+// The roles are created for each engine if they do not exist yet, so it can be used with multiple engines.
+func RegisterResourceServicePermissions(rbac grpc_rbac.RBAC) error {
+	var errs []error
+	// Register Admin role
+	if err := grpc_rbac.Grant(rbac, ResourceServiceRoles.Admin.ID()); err != nil {
+		errs = append(errs, err)
 	}
-	if err := ResourceServiceRoles.Writer.Assign(ResourceServicePermissions.Update); err != nil {
-		panic(err)
+	// Register Reader role
+	if err := grpc_rbac.Grant(rbac, ResourceServiceRoles.Reader.ID(),
+		ResourceServicePermissions.Read,
+		ResourceServicePermissions.List,
+	); err != nil {
+		errs = append(errs, err)
 	}
-	if err := ResourceServiceRoles.Writer.Assign(ResourceServicePermissions.Delete); err != nil {
-		panic(err)
+	// Register Watcher role
+	if err := grpc_rbac.Grant(rbac, ResourceServiceRoles.Watcher.ID(),
+		ResourceServicePermissions.Watch,
+	); err != nil {
+		errs = append(errs, err)
 	}
 	// Register Writer role
-	if err := rbac.Add(ResourceServiceRoles.Writer); err != nil {
-		panic(err)
+	if err := grpc_rbac.Grant(rbac, ResourceServiceRoles.Writer.ID(),
+		ResourceServicePermissions.Create,
+		ResourceServicePermissions.Update,
+		ResourceServicePermissions.Delete,
+	); err != nil {
+		errs = append(errs, err)
 	}
 
 	// Assign Admin parents
-	if err := rbac.SetParent(ResourceServiceRoles.Admin.ID(), ResourceServiceRoles.Writer.ID()); err != nil {
-		panic(err)
-	}
-	if err := rbac.SetParent(ResourceServiceRoles.Admin.ID(), ResourceServiceRoles.Reader.ID()); err != nil {
-		panic(err)
-	}
-	if err := rbac.SetParent(ResourceServiceRoles.Admin.ID(), ResourceServiceRoles.Watcher.ID()); err != nil {
-		panic(err)
+	if err := grpc_rbac.Inherit(rbac, ResourceServiceRoles.Admin.ID(), ResourceServiceRoles.Writer.ID(), ResourceServiceRoles.Reader.ID(), ResourceServiceRoles.Watcher.ID()); err != nil {
+		errs = append(errs, err)
 	}
 
+	// Describe ResourceService roles
+	rbac.DescribeRoles(
+		ResourceServiceRoles.Admin.Info(),
+		ResourceServiceRoles.Reader.Info(),
+		ResourceServiceRoles.Watcher.Info(),
+		ResourceServiceRoles.Writer.Info(),
+	)
+
+	// Register example.Resource fields rules
+	rbac.RegisterFields("example.Resource", grpc_rbac.FieldRules{
+		"owner":  {Read: []string{"example.ResourceService.Admin"}},
+		"status": {Write: []string{"example.ResourceService.Admin"}},
+	})
+
 	// Register ResourceService Service rules
-	rbac.Register(&ResourceService_ServiceDesc)
+	if err := rbac.RegisterMethods(
+		string(ResourceServiceMethodCreate),
+		string(ResourceServiceMethodRead),
+		string(ResourceServiceMethodUpdate),
+		string(ResourceServiceMethodDelete),
+		string(ResourceServiceMethodList),
+		string(ResourceServiceMethodWatch),
+	); err != nil {
+		errs = append(errs, err)
+	}
+	return grpc_rbac.JoinErrors(errs...)
 }
@@ -19,11 +19,17 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ResourceServiceClient interface {
+	// Create creates a resource.
 	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	// Read returns a resource by id.
 	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (*ReadResponse, error)
+	// Update updates a resource.
 	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
+	// Delete deletes a resource.
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// List returns all the resources.
 	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	// Watch streams the resources events.
 	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (ResourceService_WatchClient, error)
 }
 
@@ -116,11 +122,17 @@ func (x *resourceServiceWatchClient) Recv() (*Event, error) {
 // All implementations must embed UnimplementedResourceServiceServer
 // for forward compatibility
 type ResourceServiceServer interface {
+	// Create creates a resource.
 	Create(context.Context, *CreateRequest) (*CreateResponse, error)
+	// Read returns a resource by id.
 	Read(context.Context, *ReadRequest) (*ReadResponse, error)
+	// Update updates a resource.
 	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
+	// Delete deletes a resource.
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// List returns all the resources.
 	List(context.Context, *ListRequest) (*ListResponse, error)
+	// Watch streams the resources events.
 	Watch(*WatchRequest, ResourceService_WatchServer) error
 	mustEmbedUnimplementedResourceServiceServer()
 }
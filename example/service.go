@@ -54,6 +54,12 @@ func (r *resourceService) Read(ctx context.Context, request *example.ReadRequest
 }
 
 func (r *resourceService) Update(ctx context.Context, request *example.UpdateRequest) (*example.UpdateResponse, error) {
+	// updating a missing resource creates it, which requires the Create permission
+	if _, ok := r.store.Load(request.GetPayload().GetId()); !ok {
+		if err := example.ResourceServiceAuthz.RequireCreate(ctx); err != nil {
+			return nil, err
+		}
+	}
 	r.store.Store(request.GetPayload().GetId(), request.GetPayload())
 	defer r.pubsub.Publish(&example.Event{Type: example.Event_UPDATED, Payload: request.Payload})
 	return &example.UpdateResponse{Result: request.GetPayload()}, nil
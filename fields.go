@@ -0,0 +1,227 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FieldRule restricts the access to a message field to a set of roles.
+type FieldRule struct {
+	// Read are the roles allowed to see the field in responses, directly or through inheritance
+	Read []string
+	// Write are the roles allowed to set the field in requests, directly or through inheritance
+	Write []string
+}
+
+// FieldRules maps a message fields names to their rule
+type FieldRules map[protoreflect.Name]FieldRule
+
+// RegisterFields registers the message fields rules. They are merged with the rules already registered
+// for the message, e.g. by another service using the same message: the roles of a field rules are added up.
+func (r *rbac) RegisterFields(message protoreflect.FullName, rules FieldRules) {
+	r.fieldsMu.Lock()
+	merged := make(FieldRules)
+	if v, ok := r.fields.Load(message); ok {
+		for k, v := range v.(FieldRules) {
+			merged[k] = v
+		}
+	}
+	for k, v := range rules {
+		merged[k] = FieldRule{Read: union(merged[k].Read, v.Read), Write: union(merged[k].Write, v.Write)}
+	}
+	r.fields.Store(message, merged)
+	r.fieldsMu.Unlock()
+	for _, v := range []*sync.Map{&r.masked, &r.protected} {
+		v.Range(func(key, _ interface{}) bool {
+			v.Delete(key)
+			return true
+		})
+	}
+	r.NotifyPolicyChange()
+}
+
+// mask returns a copy of msg where the fields the roles are not allowed to read are cleared.
+// msg is returned as is if it does not contain any restricted field.
+func (r *rbac) mask(roles []Role, msg interface{}) interface{} {
+	m, ok := msg.(proto.Message)
+	if !ok || m == nil || !r.hasRules(&r.masked, m.ProtoReflect().Descriptor(), readRoles) {
+		return msg
+	}
+	m = proto.Clone(m)
+	r.clear(ids(roles), m.ProtoReflect())
+	return m
+}
+
+func (r *rbac) clear(ids []string, m protoreflect.Message) {
+	rules := r.rules(m.Descriptor())
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if rule, ok := rules[fd.Name()]; ok && rule.Read != nil && !r.allowed(ids, rule.Read) {
+			m.Clear(fd)
+			return true
+		}
+		rangeMessages(fd, v, func(_ string, m protoreflect.Message) bool {
+			r.clear(ids, m)
+			return true
+		})
+		return true
+	})
+}
+
+// check returns a PermissionDenied error if msg contains a field the roles are not allowed to set.
+func (r *rbac) check(roles []Role, msg interface{}) error {
+	m, ok := msg.(proto.Message)
+	if !ok || m == nil || !r.hasRules(&r.protected, m.ProtoReflect().Descriptor(), writeRoles) {
+		return nil
+	}
+	if path, write, ok := r.protectedField(ids(roles), m.ProtoReflect(), ""); ok {
+		return r.mapError(r.fieldDenied(roles, path, write))
+	}
+	return nil
+}
+
+// protectedField returns the path and the write roles of the first populated field the ids are not allowed to set
+func (r *rbac) protectedField(ids []string, m protoreflect.Message, prefix string) (path string, write []string, found bool) {
+	rules := r.rules(m.Descriptor())
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := prefix + string(fd.Name())
+		if rule, ok := rules[fd.Name()]; ok && rule.Write != nil && !r.allowed(ids, rule.Write) {
+			path, write, found = name, rule.Write, true
+			return false
+		}
+		return rangeMessages(fd, v, func(index string, m protoreflect.Message) bool {
+			path, write, found = r.protectedField(ids, m, name+index+".")
+			return !found
+		})
+	})
+	return path, write, found
+}
+
+func (r *rbac) rules(md protoreflect.MessageDescriptor) FieldRules {
+	if v, ok := r.fields.Load(md.FullName()); ok {
+		return v.(FieldRules)
+	}
+	return nil
+}
+
+// rangeMessages calls fn for every message held by the field value, with the index of the element
+// formatted as "[i]" for lists and "[key]" for maps
+func rangeMessages(fd protoreflect.FieldDescriptor, v protoreflect.Value, fn func(index string, m protoreflect.Message) bool) bool {
+	switch {
+	case fd.IsMap():
+		if fd.MapValue().Message() == nil {
+			return true
+		}
+		ok := true
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			ok = fn(fmt.Sprintf("[%v]", k.Interface()), v.Message())
+			return ok
+		})
+		return ok
+	case fd.IsList():
+		if fd.Message() == nil {
+			return true
+		}
+		for i := 0; i < v.List().Len(); i++ {
+			if !fn(fmt.Sprintf("[%d]", i), v.List().Get(i).Message()) {
+				return false
+			}
+		}
+		return true
+	case fd.Message() != nil:
+		return fn("", v.Message())
+	}
+	return true
+}
+
+func readRoles(rule FieldRule) []string {
+	return rule.Read
+}
+
+func writeRoles(rule FieldRule) []string {
+	return rule.Write
+}
+
+// hasRules reports whether the message or one of its nested messages contains fields with roles
+// returned by fn, caching the result in cache
+func (r *rbac) hasRules(cache *sync.Map, md protoreflect.MessageDescriptor, fn func(FieldRule) []string) bool {
+	if v, ok := cache.Load(md.FullName()); ok {
+		return v.(bool)
+	}
+	has := r.walkRules(md, fn, make(map[protoreflect.FullName]struct{}))
+	cache.Store(md.FullName(), has)
+	return has
+}
+
+func (r *rbac) walkRules(md protoreflect.MessageDescriptor, fn func(FieldRule) []string, seen map[protoreflect.FullName]struct{}) bool {
+	if _, ok := seen[md.FullName()]; ok {
+		return false
+	}
+	seen[md.FullName()] = struct{}{}
+	for _, rule := range r.rules(md) {
+		if fn(rule) != nil {
+			return true
+		}
+	}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.IsMap() {
+			fd = fd.MapValue()
+		}
+		if fd.Message() != nil && r.walkRules(fd.Message(), fn, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowed reports whether one of the ids is or inherits one of the roles
+func (r *rbac) allowed(ids []string, roles []string) bool {
+	for _, v := range roles {
+		if r.HasRole(v, ids...) {
+			return true
+		}
+	}
+	return false
+}
+
+func ids(roles []Role) []string {
+	var out []string
+	for _, v := range roles {
+		out = append(out, v.ID())
+	}
+	return out
+}
+
+// union returns the roles of a and b without duplicates, nil if both are nil
+func union(a, b []string) []string {
+	if a == nil && b == nil {
+		return nil
+	}
+	out := make([]string, 0, len(a)+len(b))
+	seen := make(map[string]struct{})
+	for _, v := range append(append([]string{}, a...), b...) {
+		if _, ok := seen[v]; !ok {
+			seen[v] = struct{}{}
+			out = append(out, v)
+		}
+	}
+	return out
+}
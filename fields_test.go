@@ -0,0 +1,222 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+)
+
+func TestMask(t *testing.T) {
+	r := newTestRBAC(t).(*rbac)
+	msg := &testpb.Response{Resource: &testpb.Resource{
+		Id:       "1",
+		Owner:    "owner",
+		Children: []*testpb.Resource{{Id: "2", Owner: "owner"}},
+		Refs:     map[string]*testpb.Resource{"ref": {Id: "3", Owner: "owner"}},
+	}}
+	orig := proto.Clone(msg)
+	tests := []struct {
+		name  string
+		roles []string
+		owner string
+	}{
+		{name: "admin", roles: []string{"admin"}, owner: "owner"},
+		{name: "reader", roles: []string{"reader"}},
+		{name: "reader and admin", roles: []string{"reader", "admin"}, owner: "owner"},
+		{name: "no roles"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var roles []Role
+			for _, v := range tt.roles {
+				roles = append(roles, NewStdRole(v))
+			}
+			got := r.mask(roles, msg).(*testpb.Response).GetResource()
+			for _, v := range []*testpb.Resource{got, got.GetChildren()[0], got.GetRefs()["ref"]} {
+				if v.GetOwner() != tt.owner {
+					t.Errorf("%s owner: got %q, want %q", v.GetId(), v.GetOwner(), tt.owner)
+				}
+			}
+			if !proto.Equal(msg, orig) {
+				t.Error("original message modified")
+			}
+		})
+	}
+}
+
+func TestMaskWithoutRules(t *testing.T) {
+	r := newTestRBAC(t).(*rbac)
+	msg := wrapperspb.String("value")
+	if got := r.mask(nil, msg); got != msg {
+		t.Error("message without restricted fields should be returned as is")
+	}
+	if got := r.mask(nil, "not a message"); got != "not a message" {
+		t.Errorf("got %v, want the value as is", got)
+	}
+}
+
+func TestMaskInterceptors(t *testing.T) {
+	c := serve(t, newTestRBAC(t))
+	req := &testpb.Request{Resource: &testpb.Resource{Id: "1", Owner: "owner", Children: []*testpb.Resource{{Id: "2", Owner: "owner"}}}}
+	tests := []struct {
+		role  string
+		owner string
+	}{
+		{role: "reader"},
+		{role: "admin", owner: "owner"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			ctx := withRoles(context.Background(), tt.role)
+			res, err := c.Read(ctx, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := res.GetResource().GetOwner(); got != tt.owner {
+				t.Errorf("unary owner: got %q, want %q", got, tt.owner)
+			}
+			got, err := watch(ctx, c, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 || got[0].GetOwner() != tt.owner {
+				t.Errorf("stream: got %v, want owner %q", got, tt.owner)
+			}
+		})
+	}
+}
+
+func TestMaskPublic(t *testing.T) {
+	c := serve(t, newTestRBAC(t))
+	res, err := c.Public(context.Background(), &testpb.Request{Resource: &testpb.Resource{Owner: "owner"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetResource().GetOwner() != "" {
+		t.Error("owner should be masked for anonymous callers")
+	}
+	if _, err := c.Read(context.Background(), &testpb.Request{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("got %v, want Unauthenticated", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	r := newTestRBAC(t).(*rbac)
+	tests := []struct {
+		name  string
+		role  string
+		req   *testpb.Request
+		path  string
+		allow bool
+	}{
+		{name: "unset", role: "writer", req: &testpb.Request{Resource: &testpb.Resource{Id: "1"}}, allow: true},
+		{name: "admin", role: "admin", req: &testpb.Request{Resource: &testpb.Resource{Status: testpb.Resource_ACTIVE}}, allow: true},
+		{name: "field", role: "writer", req: &testpb.Request{Resource: &testpb.Resource{Status: testpb.Resource_ACTIVE}}, path: "resource.status"},
+		{
+			name: "list",
+			role: "writer",
+			req:  &testpb.Request{Resource: &testpb.Resource{Children: []*testpb.Resource{{}, {Status: testpb.Resource_ACTIVE}}}},
+			path: "resource.children[1].status",
+		},
+		{
+			name: "map",
+			role: "writer",
+			req:  &testpb.Request{Resource: &testpb.Resource{Refs: map[string]*testpb.Resource{"ref": {Status: testpb.Resource_ACTIVE}}}},
+			path: "resource.refs[ref].status",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.check([]Role{NewStdRole(tt.role)}, tt.req)
+			if tt.allow {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if status.Code(err) != codes.PermissionDenied {
+				t.Fatalf("got %v, want PermissionDenied", err)
+			}
+			if !strings.Contains(status.Convert(err).Message(), tt.path) {
+				t.Errorf("got %v, want %s denied", err, tt.path)
+			}
+		})
+	}
+}
+
+func TestCheckInterceptors(t *testing.T) {
+	c := serve(t, newTestRBAC(t))
+	req := &testpb.Request{Resource: &testpb.Resource{Id: "1", Status: testpb.Resource_ACTIVE}}
+	tests := []struct {
+		role string
+		code codes.Code
+	}{
+		{role: "writer", code: codes.PermissionDenied},
+		{role: "admin", code: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			ctx := withRoles(context.Background(), tt.role)
+			if _, err := c.Write(ctx, req); status.Code(err) != tt.code {
+				t.Errorf("unary: got %v, want %v", err, tt.code)
+			}
+			s, err := c.Upload(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Send(req); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.CloseAndRecv(); status.Code(err) != tt.code {
+				t.Errorf("stream: got %v, want %v", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestRegisterFieldsMerge(t *testing.T) {
+	r := New().(*rbac)
+	r.RegisterFields("test.Resource", FieldRules{"owner": {Read: []string{"a.Admin"}}})
+	r.RegisterFields("test.Resource", FieldRules{"owner": {Read: []string{"b.Admin"}}, "status": {Write: []string{"b.Admin"}}})
+	r.RegisterFields("test.Resource", FieldRules{"owner": {Read: []string{"b.Admin"}}})
+	md := (&testpb.Resource{}).ProtoReflect().Descriptor()
+	got := r.rules(md)
+	want := FieldRules{
+		"owner":  {Read: []string{"a.Admin", "b.Admin"}},
+		"status": {Write: []string{"b.Admin"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	msg := &testpb.Resource{Owner: "owner"}
+	for _, v := range []string{"a.Admin", "b.Admin"} {
+		if r.mask([]Role{NewStdRole(v)}, msg).(*testpb.Resource).GetOwner() == "" {
+			t.Errorf("%s should read the owner", v)
+		}
+	}
+	if r.mask([]Role{NewStdRole("c.Admin")}, msg).(*testpb.Resource).GetOwner() != "" {
+		t.Error("c.Admin should not read the owner")
+	}
+}
@@ -4,6 +4,7 @@ go 1.16
 
 require (
 	github.com/mikespook/gorbac/v2 v2.3.3
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013
 	google.golang.org/grpc v1.47.0
 	google.golang.org/protobuf v1.28.0
 )
@@ -0,0 +1,262 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// HTTPRoute maps the HTTP requests to a registered gRPC method
+type HTTPRoute struct {
+	// Method is the HTTP method, e.g. GET, or * for any method
+	Method string
+	// Pattern is the google.api.http path template, e.g. /v1/resources/{id} or /v1/{name=resources/*}:watch
+	Pattern string
+	// FullMethod is the gRPC full method name, e.g. /example.ResourceService/Read
+	FullMethod string
+}
+
+// HTTPRoutes returns the routes of the service methods google.api.http annotations, including their additional bindings
+func HTTPRoutes(sd protoreflect.ServiceDescriptor) []HTTPRoute {
+	var out []HTTPRoute
+	for i := 0; i < sd.Methods().Len(); i++ {
+		md := sd.Methods().Get(i)
+		rule, ok := proto.GetExtension(md.Options(), annotations.E_Http).(*annotations.HttpRule)
+		if !ok || rule == nil {
+			continue
+		}
+		fullMethod := fmt.Sprintf("/%s/%s", sd.FullName(), md.Name())
+		for _, v := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+			if method, pattern := httpPattern(v); pattern != "" {
+				out = append(out, HTTPRoute{Method: method, Pattern: pattern, FullMethod: fullMethod})
+			}
+		}
+	}
+	return out
+}
+
+func httpPattern(rule *annotations.HttpRule) (method, pattern string) {
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		return p.Custom.GetKind(), p.Custom.GetPath()
+	}
+	return "", ""
+}
+
+// HTTPMiddleware returns a middleware authorizing the HTTP requests, e.g. of a grpc-gateway mux,
+// as calls to the gRPC method of the first matching route.
+// The RoleFunc is called with the request headers as incoming metadata, and the handler receives
+// the caller roles in its request context, see RolesFromContext.
+// The requests not matching any route are denied. The errors are written as google.rpc.Status JSON objects,
+// with the 401 status code for Unauthenticated and 403 for PermissionDenied.
+// As the request body is not decoded, the fields write restrictions are not enforced.
+func (r *rbac) HTTPMiddleware(routes ...HTTPRoute) func(http.Handler) http.Handler {
+	rt := newRouter(routes)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			fullMethod, ok := rt.match(req.Method, req.URL.Path)
+			if !ok {
+				writeHTTPError(w, r.mapError(fmt.Errorf("%w: no route for %s %s", ErrPermissionDenied, req.Method, req.URL.Path)))
+				return
+			}
+			ctx := context.WithValue(req.Context(), key{}, r)
+			roles, err := r.match(metadata.NewIncomingContext(ctx, headersMetadata(req.Header)), fullMethod)
+			if err != nil {
+				writeHTTPError(w, err)
+				return
+			}
+			next.ServeHTTP(w, req.WithContext(context.WithValue(ctx, rolesKey{}, staticRoles(roles))))
+		})
+	}
+}
+
+// headersMetadata returns the headers as metadata, with lower case keys
+func headersMetadata(h http.Header) metadata.MD {
+	md := make(metadata.MD, len(h))
+	for k, v := range h {
+		md.Append(k, v...)
+	}
+	return md
+}
+
+func writeHTTPError(w http.ResponseWriter, err error) {
+	s := status.Convert(err)
+	b, err := protojson.Marshal(s.Proto())
+	if err != nil {
+		b = []byte(fmt.Sprintf(`{"code":%d,"message":%q}`, s.Code(), s.Message()))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus(s.Code()))
+	_, _ = w.Write(b)
+}
+
+// httpStatus returns the HTTP status code of the gRPC code, as grpc-gateway does
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// router matches the requests against the routes, in order
+type router []route
+
+type route struct {
+	method     string
+	segments   []string
+	verb       string
+	fullMethod string
+}
+
+func newRouter(routes []HTTPRoute) router {
+	var out router
+	for _, v := range routes {
+		path, verb := splitVerb(v.Pattern)
+		out = append(out, route{method: v.Method, segments: templateSegments(path), verb: verb, fullMethod: v.FullMethod})
+	}
+	return out
+}
+
+func (r router) match(method, path string) (string, bool) {
+	path, verb := splitVerb(path)
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for _, v := range r {
+		if v.method != "*" && !strings.EqualFold(v.method, method) || v.verb != verb {
+			continue
+		}
+		if matchSegments(v.segments, segments) {
+			return v.fullMethod, true
+		}
+	}
+	return "", false
+}
+
+// splitVerb splits the ":verb" suffix of the last path segment
+func splitVerb(path string) (string, string) {
+	i := strings.LastIndex(path, "/")
+	j := strings.LastIndex(path, ":")
+	// the verb must be in the last segment and outside of a variable
+	if j <= i || strings.Contains(path[j:], "}") {
+		return path, ""
+	}
+	return path[:j], path[j+1:]
+}
+
+// templateSegments returns the path template segments, where the variables are replaced by their segments,
+// e.g. /v1/{name=resources/*}/{id} gives v1, resources, *, *
+func templateSegments(path string) []string {
+	var out []string
+	for _, v := range splitTemplate(strings.TrimPrefix(path, "/")) {
+		if !strings.HasPrefix(v, "{") {
+			out = append(out, v)
+			continue
+		}
+		v = strings.TrimSuffix(strings.TrimPrefix(v, "{"), "}")
+		if i := strings.Index(v, "="); i >= 0 {
+			out = append(out, strings.Split(v[i+1:], "/")...)
+		} else {
+			out = append(out, "*")
+		}
+	}
+	return out
+}
+
+// splitTemplate splits the template on the slashes outside of the variables
+func splitTemplate(path string) []string {
+	var out []string
+	depth, start := 0, 0
+	for i, c := range path {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '/':
+			if depth == 0 {
+				out = append(out, path[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(out, path[start:])
+}
+
+// matchSegments matches the path segments against the template segments,
+// * matching one segment and ** any number of segments
+func matchSegments(template, path []string) bool {
+	for i, v := range template {
+		switch v {
+		case "**":
+			for j := i; j <= len(path); j++ {
+				if matchSegments(template[i+1:], path[j:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if i >= len(path) || path[i] == "" {
+				return false
+			}
+		default:
+			if i >= len(path) || path[i] != v {
+				return false
+			}
+		}
+	}
+	return len(template) == len(path)
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	_ "google.golang.org/protobuf/types/known/emptypb"
+)
+
+var testRoutes = []HTTPRoute{
+	{Method: http.MethodGet, Pattern: "/v1/resources/{id}", FullMethod: readMethod},
+	{Method: http.MethodPost, Pattern: "/v1/resources", FullMethod: writeMethod},
+	{Method: http.MethodGet, Pattern: "/v1/{name=resources/*}:watch", FullMethod: watchMethod},
+	{Method: "*", Pattern: "/v1/public/**", FullMethod: publicMethod},
+}
+
+func TestRouter(t *testing.T) {
+	rt := newRouter(testRoutes)
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{method: http.MethodGet, path: "/v1/resources/1", want: readMethod},
+		{method: "get", path: "/v1/resources/1", want: readMethod},
+		{method: http.MethodGet, path: "/v1/resources/"},
+		{method: http.MethodGet, path: "/v1/resources/1/children"},
+		{method: http.MethodPost, path: "/v1/resources", want: writeMethod},
+		{method: http.MethodPut, path: "/v1/resources"},
+		{method: http.MethodGet, path: "/v1/resources/1:watch", want: watchMethod},
+		{method: http.MethodGet, path: "/v1/resources/1:list"},
+		{method: http.MethodDelete, path: "/v1/public", want: publicMethod},
+		{method: http.MethodGet, path: "/v1/public/a/b/c", want: publicMethod},
+		{method: http.MethodGet, path: "/v2/resources/1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			got, ok := rt.match(tt.method, tt.path)
+			if ok != (tt.want != "") || got != tt.want {
+				t.Errorf("got %q %v, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	var roles []Role
+	h := newTestRBAC(t).HTTPMiddleware(testRoutes...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		roles, _ = RolesFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		role   string
+		want   int
+		code   codes.Code
+	}{
+		{name: "allowed", method: http.MethodGet, path: "/v1/resources/1", role: "reader", want: http.StatusNoContent},
+		{name: "verb", method: http.MethodGet, path: "/v1/resources/1:watch", role: "admin", want: http.StatusNoContent},
+		{name: "denied", method: http.MethodPost, path: "/v1/resources", role: "reader", want: http.StatusForbidden, code: codes.PermissionDenied},
+		{name: "unauthenticated", method: http.MethodGet, path: "/v1/resources/1", want: http.StatusUnauthorized, code: codes.Unauthenticated},
+		{name: "no route", method: http.MethodGet, path: "/v2/resources/1", role: "admin", want: http.StatusForbidden, code: codes.PermissionDenied},
+		{name: "public", method: http.MethodGet, path: "/v1/public/health", want: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles = nil
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.role != "" {
+				req.Header.Set("Role", tt.role)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if tt.code == codes.OK {
+				if tt.role != "" && (len(roles) != 1 || roles[0].ID() != tt.role) {
+					t.Errorf("unexpected handler roles %v", roles)
+				}
+				return
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("got content type %q", ct)
+			}
+			var s spb.Status
+			if err := protojson.Unmarshal(w.Body.Bytes(), &s); err != nil {
+				t.Fatal(err)
+			}
+			if codes.Code(s.GetCode()) != tt.code {
+				t.Errorf("got code %v, want %v", codes.Code(s.GetCode()), tt.code)
+			}
+		})
+	}
+}
+
+func TestHTTPRoutes(t *testing.T) {
+	opts := func(rule *annotations.HttpRule) *descriptorpb.MethodOptions {
+		o := &descriptorpb.MethodOptions{}
+		proto.SetExtension(o, annotations.E_Http, rule)
+		return o
+	}
+	method := func(name string, o *descriptorpb.MethodOptions) *descriptorpb.MethodDescriptorProto {
+		return &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(name),
+			InputType:  proto.String(".google.protobuf.Empty"),
+			OutputType: proto.String(".google.protobuf.Empty"),
+			Options:    o,
+		}
+	}
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("http_test.proto"),
+		Package:    proto.String("test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/empty.proto"},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("TestService"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				method("Read", opts(&annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Get{Get: "/v1/resources/{id}"},
+					AdditionalBindings: []*annotations.HttpRule{{
+						Pattern: &annotations.HttpRule_Get{Get: "/v1/{name=resources/*}"},
+					}},
+				})),
+				method("Write", opts(&annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Kind: "WRITE", Path: "/v1/resources"}},
+				})),
+				method("Public", nil),
+			},
+		}},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []HTTPRoute{
+		{Method: http.MethodGet, Pattern: "/v1/resources/{id}", FullMethod: readMethod},
+		{Method: http.MethodGet, Pattern: "/v1/{name=resources/*}", FullMethod: readMethod},
+		{Method: "WRITE", Pattern: "/v1/resources", FullMethod: writeMethod},
+	}
+	if got := HTTPRoutes(fd.Services().Get(0)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ActAsMetadataKey is the metadata key holding the principal the caller impersonates
+const ActAsMetadataKey = "act-as"
+
+// ImpersonatePermission returns the permission to impersonate the principals holding the role,
+// or any principal if role is empty. It should only be granted to a dedicated impersonator role.
+func ImpersonatePermission(role string) Permission {
+	if role == "" {
+		return NewLayerPermission("impersonate")
+	}
+	return NewLayerPermission("impersonate", role)
+}
+
+// TargetRoleFunc resolves the roles of the impersonated principal
+type TargetRoleFunc func(ctx context.Context, target string) ([]Role, error)
+
+// CallerFunc resolves the identity of the caller principal, e.g. the subject of its credentials
+type CallerFunc func(ctx context.Context) string
+
+// Impersonation is the audit event of an impersonation attempt
+type Impersonation struct {
+	// Method is the called full method, if known
+	Method string
+	// Caller is the impersonator principal, as resolved by the CallerFunc
+	Caller string
+	// Target is the impersonated principal, as sent in the act-as metadata
+	Target string
+	// CallerRoles are the impersonator roles ids
+	CallerRoles []string
+	// TargetRoles are the impersonated principal roles ids
+	TargetRoles []string
+	// Err is the reason the impersonation failed, nil if it was allowed
+	Err error
+}
+
+// ImpersonationAudit records the impersonation events, the caller identity can be read from the context
+type ImpersonationAudit func(ctx context.Context, event Impersonation)
+
+// ImpersonationRoleFunc returns a RoleFunc evaluating the calls carrying the ActAsMetadataKey metadata as the
+// target principal: the roles are the target ones, resolved with target, if the caller roles, resolved with caller,
+// are granted the ImpersonatePermission of every target role. The targets without roles can only be impersonated
+// with the ImpersonatePermission of any principal. The impersonation is denied otherwise, including when the target
+// roles cannot be resolved. audit, if not nil, is called with both identities, the caller one being resolved with
+// identity if not nil, on every impersonation attempt, i.e. on every roles resolution.
+// The calls without the act-as metadata are resolved with caller.
+func ImpersonationRoleFunc(caller RoleFunc, identity CallerFunc, target TargetRoleFunc, audit ImpersonationAudit) RoleFunc {
+	return func(ctx context.Context) ([]Role, error) {
+		roles, err := caller(ctx)
+		if err != nil {
+			return nil, err
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		v := md.Get(ActAsMetadataKey)
+		if len(v) == 0 {
+			return roles, nil
+		}
+		e := Impersonation{Target: v[0], CallerRoles: ids(roles)}
+		e.Method, _ = grpc.Method(ctx)
+		if identity != nil {
+			e.Caller = identity(ctx)
+		}
+		targets, err := impersonate(ctx, roles, v[0], target)
+		e.TargetRoles, e.Err = ids(targets), err
+		if audit != nil {
+			audit(ctx, e)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return targets, nil
+	}
+}
+
+// impersonate returns the target roles, and an error if the roles are not allowed to impersonate the target
+func impersonate(ctx context.Context, roles []Role, target string, fn TargetRoleFunc) ([]Role, error) {
+	if len(roles) == 0 {
+		return nil, fmt.Errorf("%w: impersonation requires an authenticated caller", ErrUnauthenticated)
+	}
+	r, ok := FromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing rbac engine in context", ErrRoleResolution)
+	}
+	callers := ids(roles)
+	targets, err := fn(ctx, target)
+	if err != nil {
+		// the impersonation is denied whatever the target resolution failure
+		return nil, fmt.Errorf("%w: %v: cannot impersonate %s: %v", ErrPermissionDenied, callers, target, err)
+	}
+	if len(targets) == 0 && !r.AnyGranted(callers, ImpersonatePermission(""), nil) {
+		return nil, fmt.Errorf("%w: %v: not allowed to impersonate %s without roles", ErrPermissionDenied, callers, target)
+	}
+	for _, v := range targets {
+		if !r.AnyGranted(callers, ImpersonatePermission(v.ID()), nil) {
+			return targets, fmt.Errorf("%w: %v: not allowed to impersonate %s with role %s", ErrPermissionDenied, callers, target, v.ID())
+		}
+	}
+	return targets, nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+)
+
+// testAudit records the impersonation events
+type testAudit struct {
+	mu     sync.Mutex
+	events []Impersonation
+}
+
+func (a *testAudit) audit(_ context.Context, e Impersonation) {
+	a.mu.Lock()
+	a.events = append(a.events, e)
+	a.mu.Unlock()
+}
+
+func (a *testAudit) reset() []Impersonation {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	events := a.events
+	a.events = nil
+	return events
+}
+
+// targetRoles resolves the targets roles: alice is a reader, root an admin and guest has no roles
+func targetRoles(_ context.Context, target string) ([]Role, error) {
+	switch target {
+	case "alice":
+		return []Role{NewStdRole("reader")}, nil
+	case "root":
+		return []Role{NewStdRole("admin")}, nil
+	case "guest":
+		return nil, nil
+	}
+	return nil, errors.New("unknown target")
+}
+
+// callerName resolves the caller identity from the incoming "user" metadata
+func callerName(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get("user"); len(v) != 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func TestImpersonationRoleFunc(t *testing.T) {
+	a := &testAudit{}
+	r := newTestRBAC(t, WithRoleFunc(ImpersonationRoleFunc(incomingRoles, callerName, targetRoles, a.audit)))
+	for _, err := range []error{
+		Grant(r, "support", ImpersonatePermission("reader")),
+		Grant(r, "superuser", ImpersonatePermission("")),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := serve(t, r)
+	tests := []struct {
+		name   string
+		roles  []string
+		target string
+		code   codes.Code
+		// event is the expected audit event, without method, none if nil
+		event *Impersonation
+		err   error
+	}{
+		{
+			name:  "without act-as",
+			roles: []string{"reader"},
+		},
+		{
+			name:   "allowed",
+			roles:  []string{"support"},
+			target: "alice",
+			event:  &Impersonation{Target: "alice", CallerRoles: []string{"support"}, TargetRoles: []string{"reader"}},
+		},
+		{
+			name:   "not allowed for the target role",
+			roles:  []string{"support"},
+			target: "root",
+			code:   codes.PermissionDenied,
+			event:  &Impersonation{Target: "root", CallerRoles: []string{"support"}, TargetRoles: []string{"admin"}},
+			err:    ErrPermissionDenied,
+		},
+		{
+			name:   "allowed for any target",
+			roles:  []string{"superuser"},
+			target: "root",
+			event:  &Impersonation{Target: "root", CallerRoles: []string{"superuser"}, TargetRoles: []string{"admin"}},
+		},
+		{
+			name:   "not an impersonator",
+			roles:  []string{"admin"},
+			target: "alice",
+			code:   codes.PermissionDenied,
+			event:  &Impersonation{Target: "alice", CallerRoles: []string{"admin"}, TargetRoles: []string{"reader"}},
+			err:    ErrPermissionDenied,
+		},
+		{
+			name:   "unauthenticated caller",
+			target: "alice",
+			code:   codes.Unauthenticated,
+			event:  &Impersonation{Target: "alice"},
+			err:    ErrUnauthenticated,
+		},
+		{
+			name:   "unknown target",
+			roles:  []string{"superuser"},
+			target: "ghost",
+			code:   codes.PermissionDenied,
+			event:  &Impersonation{Target: "ghost", CallerRoles: []string{"superuser"}},
+			err:    ErrPermissionDenied,
+		},
+		{
+			name:   "target without roles",
+			roles:  []string{"support"},
+			target: "guest",
+			code:   codes.PermissionDenied,
+			event:  &Impersonation{Target: "guest", CallerRoles: []string{"support"}},
+			err:    ErrPermissionDenied,
+		},
+		{
+			name:   "target without roles for any target",
+			roles:  []string{"superuser"},
+			target: "guest",
+			code:   codes.Unauthenticated,
+			event:  &Impersonation{Target: "guest", CallerRoles: []string{"superuser"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a.reset()
+			ctx := metadata.AppendToOutgoingContext(withRoles(context.Background(), tt.roles...), "user", "bob")
+			if tt.target != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, ActAsMetadataKey, tt.target)
+			}
+			_, err := c.Read(ctx, &testpb.Request{})
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("Read: expected %v, got %v: %v", tt.code, got, err)
+			}
+			_, err = watch(ctx, c, &testpb.Request{Resource: &testpb.Resource{Children: []*testpb.Resource{{Id: "1"}}}})
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("Watch: expected %v, got %v: %v", tt.code, got, err)
+			}
+			events := a.reset()
+			if tt.event == nil {
+				if len(events) != 0 {
+					t.Fatalf("expected no audit event, got %v", events)
+				}
+				return
+			}
+			if len(events) != 2 {
+				t.Fatalf("expected 2 audit events, got %d", len(events))
+			}
+			for i, method := range []string{readMethod, watchMethod} {
+				e := events[i]
+				if e.Method != method {
+					t.Errorf("expected event for %s, got %s", method, e.Method)
+				}
+				if e.Caller != "bob" {
+					t.Errorf("expected event caller bob, got %q", e.Caller)
+				}
+				if e.Target != tt.event.Target || !reflect.DeepEqual(e.CallerRoles, tt.event.CallerRoles) || !reflect.DeepEqual(e.TargetRoles, tt.event.TargetRoles) {
+					t.Errorf("expected event %+v, got %+v", *tt.event, e)
+				}
+				if (e.Err != nil) != (tt.err != nil) {
+					t.Errorf("unexpected event error: %v", e.Err)
+				}
+				if tt.err != nil && !errors.Is(e.Err, tt.err) {
+					t.Errorf("expected event error %v, got %v", tt.err, e.Err)
+				}
+			}
+		})
+	}
+}
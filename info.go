@@ -0,0 +1,59 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/mikespook/gorbac/v2"
+)
+
+func (r *rbac) DescribeRoles(infos ...RoleInfo) {
+	for _, v := range infos {
+		r.infos.Store(v.ID, v)
+	}
+}
+
+func (r *rbac) RoleInfo(id string) (RoleInfo, bool) {
+	role, _, err := r.rbac.Get(id)
+	if errors.Is(err, gorbac.ErrRoleNotExist) {
+		return RoleInfo{}, false
+	}
+	return r.info(role), true
+}
+
+func (r *rbac) RoleInfos() []RoleInfo {
+	var out []RoleInfo
+	_ = r.Walk(func(role Role, _ []string) error {
+		out = append(out, r.info(role))
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// info returns the role described information, or the information of the role itself if it is a DescribedRole
+func (r *rbac) info(role Role) RoleInfo {
+	if v, ok := r.infos.Load(role.ID()); ok {
+		return v.(RoleInfo)
+	}
+	if v, ok := role.(interface{ Info() RoleInfo }); ok {
+		return v.Info()
+	}
+	return RoleInfo{ID: role.ID()}
+}
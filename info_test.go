@@ -0,0 +1,77 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDescribedRole(t *testing.T) {
+	info := RoleInfo{
+		ID:          "admin",
+		DisplayName: "Administrator",
+		Description: "Full access.",
+		Deprecated:  true,
+		Labels:      map[string]string{"tier": "privileged"},
+	}
+	r := NewDescribedRole(info)
+	if r.ID() != "admin" || r.DisplayName() != "Administrator" || r.Description() != "Full access." || !r.Deprecated() {
+		t.Errorf("unexpected role %v", r.Info())
+	}
+	if !reflect.DeepEqual(r.Labels(), info.Labels) || !reflect.DeepEqual(r.Info(), info) {
+		t.Errorf("unexpected role info %v", r.Info())
+	}
+	if got := NewDescribedRole(RoleInfo{ID: "reader"}).DisplayName(); got != "reader" {
+		t.Errorf("display name: got %q, want the role id", got)
+	}
+}
+
+func TestRoleInfo(t *testing.T) {
+	r := newTestRBAC(t)
+	if err := r.Add(NewDescribedRole(RoleInfo{ID: "auditor", Description: "Audit the resources."})); err != nil {
+		t.Fatal(err)
+	}
+	r.DescribeRoles(
+		RoleInfo{ID: "admin", DisplayName: "Administrator", Labels: map[string]string{"tier": "privileged"}},
+		RoleInfo{ID: "reader", Deprecated: true},
+	)
+	tests := []struct {
+		id   string
+		want RoleInfo
+		ok   bool
+	}{
+		{id: "admin", want: RoleInfo{ID: "admin", DisplayName: "Administrator", Labels: map[string]string{"tier": "privileged"}}, ok: true},
+		{id: "auditor", want: RoleInfo{ID: "auditor", Description: "Audit the resources."}, ok: true},
+		{id: "reader", want: RoleInfo{ID: "reader", Deprecated: true}, ok: true},
+		{id: "writer", want: RoleInfo{ID: "writer"}, ok: true},
+		{id: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got, ok := r.RoleInfo(tt.id)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v %v, want %v %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+	var ids []string
+	for _, v := range r.RoleInfos() {
+		ids = append(ids, v.ID)
+	}
+	if want := []string{"admin", "auditor", "reader", "writer"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("role infos: got %v, want %v", ids, want)
+	}
+}
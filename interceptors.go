@@ -17,11 +17,13 @@ package grpc_rbac
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
 )
 
 type Interceptors interface {
@@ -34,26 +36,46 @@ type Interceptors interface {
 func (r *rbac) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 		ctx = context.WithValue(ctx, key{}, r)
-		if err := r.match(ctx, info.FullMethod); err != nil {
+		roles, err := r.match(ctx, info.FullMethod)
+		if err != nil {
 			return nil, err
 		}
-		return handler(ctx, req)
+		if err := r.check(roles, req); err != nil {
+			return nil, err
+		}
+		resp, err = handler(context.WithValue(ctx, rolesKey{}, staticRoles(roles)), req)
+		if err != nil {
+			return nil, err
+		}
+		return r.mask(roles, resp), nil
 	}
 }
 
 func (r *rbac) StreamServerInterceptor() grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		ctx := context.WithValue(ss.Context(), key{}, r)
-		if err := r.match(ss.Context(), info.FullMethod); err != nil {
+		base := context.WithValue(ss.Context(), key{}, r)
+		roles, err := r.match(base, info.FullMethod)
+		if err != nil {
 			return err
 		}
-		return handler(srv, &wrapper{ctx: ctx, ServerStream: ss})
+		ctx, cancel := context.WithCancel(base)
+		defer cancel()
+		w := &wrapper{base: base, cancel: cancel, ServerStream: ss, rbac: r, method: info.FullMethod, roles: roles}
+		w.ctx = context.WithValue(ctx, rolesKey{}, w)
+		if r.reauth {
+			go w.watch()
+		}
+		err = handler(srv, w)
+		if werr := w.error(); werr != nil {
+			return werr
+		}
+		return err
 	}
 }
 
 func (r *rbac) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		if err := r.match(ctx, method); err != nil {
+		if err := r.preflight(ctx, cc, method, opts); err != nil {
 			return err
 		}
 		return invoker(ctx, method, req, reply, cc, opts...)
@@ -62,42 +84,129 @@ func (r *rbac) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
 
 func (r *rbac) StreamClientInterceptor() grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
-		if err := r.match(ctx, method); err != nil {
+		if err := r.preflight(ctx, cc, method, opts); err != nil {
 			return nil, err
 		}
 		return streamer(ctx, desc, cc, method, opts...)
 	}
 }
 
-func (r *rbac) match(ctx context.Context, fullMethod string) error {
-	roles, err := r.roleFunc(ctx)
-	if err != nil {
+// preflight authorizes an outgoing call before it is sent.
+// If a client RoleFunc is set, it is called with the outgoing context where the metadata of the per-RPC
+// credentials set with WithClientCredentials and of the call options ones are appended.
+func (r *rbac) preflight(ctx context.Context, cc *grpc.ClientConn, fullMethod string, opts []grpc.CallOption) error {
+	if r.clientRoleFunc == nil {
+		_, err := r.match(ctx, fullMethod)
 		return err
 	}
+	creds := append([]credentials.PerRPCCredentials{}, r.clientCreds...)
+	for _, v := range opts {
+		if o, ok := v.(grpc.PerRPCCredsCallOption); ok && o.Creds != nil {
+			creds = append(creds, o.Creds)
+		}
+	}
+	uri := audience(cc, fullMethod)
+	for _, v := range creds {
+		md, err := v.GetRequestMetadata(ctx, uri)
+		if err != nil {
+			return r.mapError(fmt.Errorf("%w: failed to get request metadata: %v", ErrUnauthenticated, err))
+		}
+		for k, v := range md {
+			ctx = metadata.AppendToOutgoingContext(ctx, k, v)
+		}
+	}
+	ctx, decide := r.authorize(ctx, fullMethod, false)
+	roles, err := r.resolve(ctx, r.clientRoleFunc)
+	if err != nil {
+		if r.isPublic(fullMethod) {
+			decide(nil, nil, nil)
+			return nil
+		}
+		err = resolutionErr(err)
+		decide(nil, nil, err)
+		return r.mapError(err)
+	}
+	role, err := r.matchRoles(roles, fullMethod)
+	decide(roles, role, err)
+	return r.mapError(err)
+}
+
+func (r *rbac) match(ctx context.Context, fullMethod string) ([]Role, error) {
+	return r.evaluate(ctx, fullMethod, false)
+}
+
+// rematch is match for the re-authorization of a server stream, its decision is recorded as such
+func (r *rbac) rematch(ctx context.Context, fullMethod string) ([]Role, error) {
+	return r.evaluate(ctx, fullMethod, true)
+}
+
+// evaluate resolves the caller roles and matches them against the method, recording the decision
+func (r *rbac) evaluate(ctx context.Context, fullMethod string, reauth bool) ([]Role, error) {
+	ctx, decide := r.authorize(ctx, fullMethod, reauth)
+	roles, err := r.resolve(ctx, r.roleFunc)
+	if err != nil {
+		if r.isPublic(fullMethod) {
+			decide(nil, nil, nil)
+			return nil, nil
+		}
+		err = resolutionErr(err)
+		decide(nil, nil, err)
+		return nil, r.mapError(err)
+	}
+	role, err := r.matchRoles(roles, fullMethod)
+	decide(roles, role, err)
+	if err != nil {
+		return nil, r.mapError(err)
+	}
+	return roles, nil
+}
+
+// matchRoles returns the first role allowed to call the method, nil for the public methods.
+// The errors are not mapped.
+func (r *rbac) matchRoles(roles []Role, fullMethod string) (Role, error) {
+	if r.isPublic(fullMethod) {
+		return nil, nil
+	}
 	v, ok := r.reg.Load(fullMethod)
 	if !ok {
-		return fmt.Errorf("permission for '%s' not found", fullMethod)
+		return nil, fmt.Errorf("%w: permission for '%s' not found", ErrPermissionDenied, fullMethod)
+	}
+	if len(roles) == 0 {
+		return nil, ErrUnauthenticated
 	}
 	perm := v.(GRPCPermission)
-	granted := false
-	var ids []string
 	for _, v := range roles {
-		if granted = r.rbac.IsGranted(v.ID(), perm, nil); granted {
-			break
+		if r.rbac.IsGranted(v.ID(), perm, nil) {
+			return v, nil
 		}
-		ids = append(ids, v.ID())
 	}
-	if !granted {
-		return status.Errorf(codes.PermissionDenied, "[%s]: not allowed to call %s", strings.Join(ids, ", "), fullMethod)
-	}
-	return nil
+	return nil, r.methodDenied(roles, perm)
 }
 
-type wrapper struct {
-	grpc.ServerStream
-	ctx context.Context
+// audience returns the URI the per-RPC credentials are called with, built as grpc does
+// from the connection authority and the method service, e.g. https://example.com/pkg.Service
+func audience(cc *grpc.ClientConn, fullMethod string) string {
+	var host string
+	if cc != nil {
+		host = strings.TrimSuffix(authority(cc.Target()), ":443")
+	}
+	if i := strings.LastIndex(fullMethod, "/"); i > 0 {
+		fullMethod = fullMethod[:i]
+	}
+	return "https://" + host + fullMethod
 }
 
-func (w *wrapper) Context() context.Context {
-	return w.ctx
+// authority returns the default authority of the dial target, i.e. its endpoint
+func authority(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || resolver.Get(u.Scheme) == nil {
+		return target
+	}
+	if u.Scheme == "unix" || u.Scheme == "unix-abstract" {
+		return "localhost"
+	}
+	if u.Opaque != "" {
+		return u.Opaque
+	}
+	return strings.TrimPrefix(u.Path, "/")
 }
@@ -0,0 +1,137 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+)
+
+// roleCreds are per-RPC credentials sending the role in the "role" metadata and recording the URIs they are called with
+type roleCreds struct {
+	role string
+	mu   sync.Mutex
+	uris []string
+}
+
+func (c *roleCreds) GetRequestMetadata(_ context.Context, uri ...string) (map[string]string, error) {
+	c.mu.Lock()
+	c.uris = append(c.uris, uri...)
+	c.mu.Unlock()
+	return map[string]string{"role": c.role}, nil
+}
+
+func (c *roleCreds) RequireTransportSecurity() bool {
+	return false
+}
+
+func TestPreflight(t *testing.T) {
+	dial := &roleCreds{role: "reader"}
+	call := &roleCreds{role: "writer"}
+	r := newTestRBAC(t, WithClientRoleFunc(OutgoingMetadataRoleFunc("role")), WithClientCredentials(dial))
+	// the server does not authorize the calls, so the denials come from the client interceptors
+	c := testpb.NewTestServiceClient(dialServer(t, grpc.NewServer(),
+		grpc.WithPerRPCCredentials(dial),
+		grpc.WithUnaryInterceptor(r.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(r.StreamClientInterceptor()),
+	))
+	ctx := context.Background()
+	if _, err := c.Read(ctx, &testpb.Request{}); err != nil {
+		t.Errorf("dial credentials: %v", err)
+	}
+	if _, err := c.Write(ctx, &testpb.Request{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("dial credentials: got %v, want PermissionDenied", err)
+	}
+	if _, err := c.Write(ctx, &testpb.Request{}, grpc.PerRPCCredentials(call)); err != nil {
+		t.Errorf("call credentials: %v", err)
+	}
+	if _, err := c.Upload(ctx); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("stream: got %v, want PermissionDenied", err)
+	}
+	if _, err := c.Upload(ctx, grpc.PerRPCCredentials(call)); err != nil {
+		t.Errorf("stream call credentials: %v", err)
+	}
+	for _, v := range append(dial.uris, call.uris...) {
+		if want := "https://bufnet/test.TestService"; v != want {
+			t.Errorf("got uri %s, want %s", v, want)
+		}
+	}
+}
+
+func TestPreflightRoleFunc(t *testing.T) {
+	r := newTestRBAC(t, WithRoleFunc(OutgoingMetadataRoleFunc("role")))
+	c := testpb.NewTestServiceClient(dialServer(t, grpc.NewServer(), grpc.WithUnaryInterceptor(r.UnaryClientInterceptor())))
+	tests := []struct {
+		method string
+		roles  []string
+		code   codes.Code
+	}{
+		{method: "Read", roles: []string{"reader"}, code: codes.OK},
+		{method: "Write", roles: []string{"reader"}, code: codes.PermissionDenied},
+		{method: "Write", code: codes.Unauthenticated},
+		{method: "Public", code: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			ctx := withRoles(context.Background(), tt.roles...)
+			var err error
+			switch tt.method {
+			case "Read":
+				_, err = c.Read(ctx, &testpb.Request{})
+			case "Write":
+				_, err = c.Write(ctx, &testpb.Request{})
+			case "Public":
+				_, err = c.Public(ctx, &testpb.Request{})
+			}
+			if status.Code(err) != tt.code {
+				t.Errorf("got %v, want %v", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestAudience(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{target: "localhost:8080", want: "https://localhost:8080/test.TestService"},
+		{target: "example.com:443", want: "https://example.com/test.TestService"},
+		{target: "dns:///example.com:443", want: "https://example.com/test.TestService"},
+		{target: "dns://8.8.8.8/example.com", want: "https://example.com/test.TestService"},
+		{target: "passthrough:///example.com:8443", want: "https://example.com:8443/test.TestService"},
+		{target: "unix:///tmp/grpc.sock", want: "https://localhost/test.TestService"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			cc, err := grpc.Dial(tt.target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cc.Close()
+			if got := audience(cc, readMethod); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"bytes"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "go.linka.cloud/grpc-rbac/rbac"
+)
+
+// UnmarshalManifest decodes a policy manifest generated by protoc-gen-go-rbac, either JSON or binary encoded.
+func UnmarshalManifest(b []byte) (*pb.Manifest, error) {
+	m := &pb.Manifest{}
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		if err := protojson.Unmarshal(b, m); err != nil {
+			return nil, fmt.Errorf("decode json manifest: %w", err)
+		}
+		return m, nil
+	}
+	if err := proto.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("decode binary manifest: %w", err)
+	}
+	return m, nil
+}
+
+// LoadManifests registers the roles, permissions and rules declared by the manifests to the rbac engine,
+// the same way the generated Register<Service>Permissions functions do, without any generated Go code.
+func LoadManifests(r RBAC, manifests ...*pb.Manifest) error {
+	var errs []error
+	for _, m := range manifests {
+		errs = append(errs, loadRoles(r, m.GetRoles())...)
+		for _, s := range m.GetServices() {
+			errs = append(errs, loadService(r, s)...)
+		}
+	}
+	return JoinErrors(errs...)
+}
+
+func loadService(r RBAC, s *pb.ManifestService) []error {
+	errs := loadRoles(r, s.GetRoles())
+	desc := &grpc.ServiceDesc{ServiceName: s.GetName()}
+	var public []string
+	for _, m := range s.GetMethods() {
+		desc.Methods = append(desc.Methods, grpc.MethodDesc{MethodName: m.GetName()})
+		perm := NewGRPCPermission(s.GetName(), m.GetName())
+		for _, v := range m.GetRoles() {
+			if err := Grant(r, v, perm); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if m.GetPublic() {
+			public = append(public, perm.ID())
+		}
+	}
+	for _, m := range s.GetMessages() {
+		rules := make(FieldRules)
+		for _, f := range m.GetFields() {
+			rules[protoreflect.Name(f.GetName())] = FieldRule{Read: f.GetRead(), Write: f.GetWrite()}
+		}
+		r.RegisterFields(protoreflect.FullName(m.GetName()), rules)
+	}
+	r.Register(desc)
+	if len(public) != 0 {
+		r.RegisterPublic(public...)
+	}
+	return errs
+}
+
+func loadRoles(r RBAC, roles []*pb.ManifestRole) []error {
+	var errs []error
+	for _, v := range roles {
+		if err := Inherit(r, v.GetId(), v.GetParents()...); err != nil {
+			errs = append(errs, err)
+		}
+		if v.Description == nil && v.DisplayName == nil && v.Deprecated == nil && len(v.Labels) == 0 {
+			continue
+		}
+		r.DescribeRoles(RoleInfo{
+			ID:          v.GetId(),
+			DisplayName: v.GetDisplayName(),
+			Description: v.GetDescription(),
+			Deprecated:  v.GetDeprecated(),
+			Labels:      v.GetLabels(),
+		})
+	}
+	return errs
+}
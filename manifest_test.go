@@ -0,0 +1,144 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+	pb "go.linka.cloud/grpc-rbac/rbac"
+)
+
+// testManifest declares the same policy as newTestRBAC
+func testManifest() *pb.Manifest {
+	method := func(name string, roles ...string) *pb.ManifestMethod {
+		return &pb.ManifestMethod{Name: proto.String(name), FullMethod: proto.String("/test.TestService/" + name), Roles: roles}
+	}
+	return &pb.Manifest{
+		File:    proto.String("internal/testpb/test.proto"),
+		Package: proto.String("test"),
+		Roles: []*pb.ManifestRole{{
+			Id:          proto.String("auditor"),
+			Parents:     []string{"reader"},
+			Description: proto.String("Audit the resources."),
+		}},
+		Services: []*pb.ManifestService{{
+			Name: proto.String("test.TestService"),
+			Roles: []*pb.ManifestRole{
+				{Id: proto.String("admin"), Parents: []string{"writer", "reader"}, DisplayName: proto.String("Administrator")},
+				{Id: proto.String("reader")},
+				{Id: proto.String("writer")},
+			},
+			Methods: []*pb.ManifestMethod{
+				method("Read", "reader"),
+				method("Watch", "reader"),
+				method("Write", "writer"),
+				method("Upload", "writer"),
+				{Name: proto.String("Public"), FullMethod: proto.String(publicMethod), Public: proto.Bool(true)},
+			},
+			Messages: []*pb.ManifestMessage{{
+				Name: proto.String("test.Resource"),
+				Fields: []*pb.ManifestField{
+					{Name: proto.String("owner"), Read: []string{"admin"}},
+					{Name: proto.String("status"), Write: []string{"admin"}},
+				},
+			}},
+		}},
+	}
+}
+
+func TestUnmarshalManifest(t *testing.T) {
+	m := testManifest()
+	j, err := protojson.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "json", data: j},
+		{name: "indented json", data: append([]byte("\n  "), j...)},
+		{name: "binary", data: b},
+		{name: "invalid json", data: []byte("{\"services\": 1}"), wantErr: true},
+		{name: "invalid binary", data: []byte{0xff}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalManifest(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && !proto.Equal(got, m) {
+				t.Errorf("got %v, want %v", got, m)
+			}
+		})
+	}
+}
+
+func TestLoadManifests(t *testing.T) {
+	r := New(WithRoleFunc(incomingRoles))
+	if err := LoadManifests(r, testManifest()); err != nil {
+		t.Fatal(err)
+	}
+	if info, ok := r.RoleInfo("auditor"); !ok || info.Description != "Audit the resources." {
+		t.Errorf("unexpected auditor info: %v", info)
+	}
+	if info, ok := r.RoleInfo("admin"); !ok || info.DisplayName != "Administrator" {
+		t.Errorf("unexpected admin info: %v", info)
+	}
+	c := serve(t, r)
+	req := &testpb.Request{Resource: &testpb.Resource{Id: "1", Owner: "owner"}}
+	tests := []struct {
+		role  string
+		read  codes.Code
+		write codes.Code
+		owner string
+	}{
+		{role: "reader", write: codes.PermissionDenied},
+		{role: "auditor", write: codes.PermissionDenied},
+		{role: "writer", read: codes.PermissionDenied},
+		{role: "admin", owner: "owner"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			ctx := withRoles(context.Background(), tt.role)
+			res, err := c.Read(ctx, req)
+			if status.Code(err) != tt.read {
+				t.Fatalf("read: got %v, want %v", err, tt.read)
+			}
+			if err == nil && res.GetResource().GetOwner() != tt.owner {
+				t.Errorf("read owner: got %q, want %q", res.GetResource().GetOwner(), tt.owner)
+			}
+			if _, err := c.Write(ctx, req); status.Code(err) != tt.write {
+				t.Errorf("write: got %v, want %v", err, tt.write)
+			}
+		})
+	}
+	if _, err := c.Public(context.Background(), req); err != nil {
+		t.Errorf("public: %v", err)
+	}
+}
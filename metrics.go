@@ -0,0 +1,99 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Outcome is the outcome of an authorization decision
+type Outcome string
+
+const (
+	// OutcomeAllowed is the outcome of an allowed call, including the public methods
+	OutcomeAllowed Outcome = "allowed"
+	// OutcomeDenied is the outcome of a call denied by the policy
+	OutcomeDenied Outcome = "denied"
+	// OutcomeUnauthenticated is the outcome of a call without caller identity
+	OutcomeUnauthenticated Outcome = "unauthenticated"
+	// OutcomeError is the outcome of a call which roles could not be resolved
+	OutcomeError Outcome = "error"
+)
+
+// Decision is an authorization decision of the interceptors
+type Decision struct {
+	// Method is the full method name, e.g. /example.ResourceService/Create
+	Method string
+	// Outcome is the decision outcome
+	Outcome Outcome
+	// Role is the id of the caller role allowed to call the method,
+	// empty if the call is not allowed or the method is public
+	Role string
+	// Roles are the caller roles ids
+	Roles []string
+	// Duration is the duration of the decision, including the roles resolution
+	Duration time.Duration
+	// Err is the decision error before its mapping, nil if the call is allowed
+	Err error
+	// Reauthorization is true for the re-checks of the already authorized server streams,
+	// see WithStreamReauthorization. The implementations should record them apart from the calls decisions.
+	Reauthorization bool
+}
+
+// Metrics records the authorization decisions, see WithMetrics.
+// The implementations must be safe for concurrent use.
+type Metrics interface {
+	// Decision records an authorization decision of the interceptors
+	Decision(ctx context.Context, d Decision)
+	// RoleResolution records the duration of a RoleFunc call and its error
+	RoleResolution(ctx context.Context, d time.Duration, err error)
+	// PolicySize records the number of roles and registered methods, on every policy change
+	PolicySize(roles, methods int)
+}
+
+func outcome(err error) Outcome {
+	switch {
+	case err == nil:
+		return OutcomeAllowed
+	case errors.Is(err, ErrUnauthenticated) || status.Code(err) == codes.Unauthenticated:
+		return OutcomeUnauthenticated
+	case errors.Is(err, ErrPermissionDenied) || status.Code(err) == codes.PermissionDenied:
+		return OutcomeDenied
+	default:
+		return OutcomeError
+	}
+}
+
+// policySize records the number of roles and registered methods
+func (r *rbac) policySize() {
+	if r.metrics == nil {
+		return
+	}
+	var roles, methods int
+	_ = r.Walk(func(Role, []string) error {
+		roles++
+		return nil
+	})
+	r.reg.Range(func(_, _ interface{}) bool {
+		methods++
+		return true
+	})
+	r.metrics.PolicySize(roles, methods)
+}
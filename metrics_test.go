@@ -0,0 +1,196 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+)
+
+// testMetrics records the decisions, roles resolutions and policy sizes
+type testMetrics struct {
+	mu          sync.Mutex
+	decisions   []Decision
+	resolutions []error
+	roles       int
+	methods     int
+}
+
+func (m *testMetrics) Decision(_ context.Context, d Decision) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.decisions = append(m.decisions, d)
+}
+
+func (m *testMetrics) RoleResolution(_ context.Context, _ time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.resolutions = append(m.resolutions, err)
+}
+
+func (m *testMetrics) PolicySize(roles, methods int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.roles, m.methods = roles, methods
+}
+
+// last returns the last recorded decision
+func (m *testMetrics) last() Decision {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.decisions) == 0 {
+		return Decision{}
+	}
+	return m.decisions[len(m.decisions)-1]
+}
+
+func TestMetrics(t *testing.T) {
+	m := &testMetrics{}
+	fail := errors.New("store unreachable")
+	r := newTestRBAC(t, WithMetrics(m), WithRoleFunc(func(ctx context.Context) ([]Role, error) {
+		roles, _ := incomingRoles(ctx)
+		if len(roles) == 1 && roles[0].ID() == "fail" {
+			return nil, fail
+		}
+		return roles, nil
+	}))
+	c := serve(t, r)
+	m.mu.Lock()
+	if m.roles != 3 || m.methods != 5 {
+		t.Errorf("policy size: got %d roles and %d methods, want 3 and 5", m.roles, m.methods)
+	}
+	m.mu.Unlock()
+	tests := []struct {
+		name  string
+		roles []string
+		call  func(ctx context.Context) error
+		want  Decision
+	}{
+		{
+			name:  "allowed",
+			roles: []string{"reader"},
+			call: func(ctx context.Context) error {
+				_, err := c.Read(ctx, &testpb.Request{})
+				return err
+			},
+			want: Decision{Method: readMethod, Outcome: OutcomeAllowed, Role: "reader", Roles: []string{"reader"}},
+		},
+		{
+			name:  "inherited",
+			roles: []string{"admin"},
+			call: func(ctx context.Context) error {
+				_, err := watch(ctx, c, &testpb.Request{})
+				return err
+			},
+			want: Decision{Method: watchMethod, Outcome: OutcomeAllowed, Role: "admin", Roles: []string{"admin"}},
+		},
+		{
+			name:  "denied",
+			roles: []string{"reader"},
+			call: func(ctx context.Context) error {
+				_, err := c.Write(ctx, &testpb.Request{})
+				return err
+			},
+			want: Decision{Method: writeMethod, Outcome: OutcomeDenied, Roles: []string{"reader"}},
+		},
+		{
+			name: "unauthenticated",
+			call: func(ctx context.Context) error {
+				_, err := c.Read(ctx, &testpb.Request{})
+				return err
+			},
+			want: Decision{Method: readMethod, Outcome: OutcomeUnauthenticated},
+		},
+		{
+			name:  "error",
+			roles: []string{"fail"},
+			call: func(ctx context.Context) error {
+				_, err := c.Read(ctx, &testpb.Request{})
+				return err
+			},
+			want: Decision{Method: readMethod, Outcome: OutcomeError},
+		},
+		{
+			name: "public",
+			call: func(ctx context.Context) error {
+				_, err := c.Public(ctx, &testpb.Request{})
+				return err
+			},
+			want: Decision{Method: publicMethod, Outcome: OutcomeAllowed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(withRoles(context.Background(), tt.roles...))
+			if (err == nil) != (tt.want.Outcome == OutcomeAllowed) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			got := m.last()
+			if got.Method != tt.want.Method || got.Outcome != tt.want.Outcome || got.Role != tt.want.Role || !reflect.DeepEqual(got.Roles, tt.want.Roles) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if (got.Err == nil) != (tt.want.Outcome == OutcomeAllowed) {
+				t.Errorf("unexpected decision error %v", got.Err)
+			}
+			if got.Duration <= 0 {
+				t.Error("missing decision duration")
+			}
+		})
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.resolutions) != len(tests) || !errors.Is(m.resolutions[4], fail) {
+		t.Errorf("unexpected roles resolutions %v", m.resolutions)
+	}
+}
+
+func TestReauthorizationMetrics(t *testing.T) {
+	m := &testMetrics{}
+	c := serve(t, newTestRBAC(t, WithMetrics(m), WithStreamReauthorization(0)))
+	s, err := c.Upload(withRoles(context.Background(), "writer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := s.Send(&testpb.Request{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.CloseAndRecv(); err != nil {
+		t.Fatal(err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var calls, reauths int
+	for _, v := range m.decisions {
+		if v.Method != uploadMethod || v.Outcome != OutcomeAllowed {
+			t.Errorf("unexpected decision %+v", v)
+		}
+		if v.Reauthorization {
+			reauths++
+		} else {
+			calls++
+		}
+	}
+	if calls != 1 || reauths < 3 {
+		t.Errorf("got %d calls decisions and %d re-authorizations, want 1 and at least 3", calls, reauths)
+	}
+}
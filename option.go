@@ -15,7 +15,10 @@
 package grpc_rbac
 
 import (
+	"time"
+
 	"github.com/mikespook/gorbac/v2"
+	"google.golang.org/grpc/credentials"
 )
 
 type Option func(r *rbac)
@@ -26,8 +29,69 @@ func WithRoleFunc(fn RoleFunc) Option {
 	}
 }
 
+// WithClientRoleFunc sets the function resolving the caller roles in the client interceptors,
+// allowing to deny the calls locally. It receives the outgoing context, where the metadata
+// of the per-RPC credentials, from WithClientCredentials and from the call options, are appended.
+// If not set, the client interceptors use the RoleFunc.
+func WithClientRoleFunc(fn RoleFunc) Option {
+	return func(r *rbac) {
+		r.clientRoleFunc = fn
+	}
+}
+
+// WithClientCredentials sets the per-RPC credentials the connections are dialed with, i.e. with
+// grpc.WithPerRPCCredentials, as they are not visible to the client interceptors.
+// Their metadata are appended to the outgoing context the client RoleFunc is called with.
+func WithClientCredentials(creds ...credentials.PerRPCCredentials) Option {
+	return func(r *rbac) {
+		r.clientCreds = append(r.clientCreds, creds...)
+	}
+}
+
 func WithAssertionFunc(fn gorbac.AssertionFunc) Option {
 	return func(r *rbac) {
 		r.assertFn = fn
 	}
 }
+
+// WithStreamReauthorization enables the authorization re-check of the server streams,
+// on every sent or received message if interval is zero, or every interval otherwise.
+// The streams are also re-checked on policy changes, see RBAC.NotifyPolicyChange.
+// The streams are ended with the authorization error when the access is lost.
+func WithStreamReauthorization(interval time.Duration) Option {
+	return func(r *rbac) {
+		r.reauth = true
+		r.reauthInterval = interval
+	}
+}
+
+// WithErrorDetails sets the level of details of the authorization denials errors,
+// ErrorDetailsReason by default. See DenialFromError to parse them on the client side.
+func WithErrorDetails(level ErrorDetailsLevel) Option {
+	return func(r *rbac) {
+		r.errDetails = level
+	}
+}
+
+// WithErrorMapper sets the function converting the role resolution and authorization errors
+// to the errors returned to the callers, DefaultErrorMapper by default
+func WithErrorMapper(fn ErrorMapper) Option {
+	return func(r *rbac) {
+		r.errMapper = fn
+	}
+}
+
+// WithMetrics sets the Metrics recording the authorization decisions of the interceptors,
+// the roles resolutions and the policy size
+func WithMetrics(m Metrics) Option {
+	return func(r *rbac) {
+		r.metrics = m
+	}
+}
+
+// WithTracer sets the Tracer tracing the authorization decisions of the interceptors and the roles resolutions
+func WithTracer(t Tracer) Option {
+	return func(r *rbac) {
+		r.tracer = t
+	}
+}
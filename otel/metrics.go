@@ -0,0 +1,121 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otel provides the OpenTelemetry implementations of the grpc_rbac.Metrics and grpc_rbac.Tracer.
+package otel
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	grpc_rbac "go.linka.cloud/grpc-rbac"
+)
+
+const instrumentationName = "go.linka.cloud/grpc-rbac"
+
+// Attributes keys
+const (
+	MethodKey  = attribute.Key("rpc.method")
+	OutcomeKey = attribute.Key("rbac.outcome")
+	RoleKey    = attribute.Key("rbac.role")
+	RolesKey   = attribute.Key("rbac.roles")
+	ErrorKey   = attribute.Key("rbac.error")
+)
+
+var _ grpc_rbac.Metrics = (*Metrics)(nil)
+
+// Metrics records the authorization decisions as OpenTelemetry metrics:
+//   - rbac.decisions: the decisions counter, by method, outcome and matched role
+//   - rbac.decision.duration: the decisions duration histogram in seconds, by method and outcome
+//   - rbac.reauthorizations: the streams re-authorizations counter, by method, outcome and matched role
+//   - rbac.role_resolution.duration: the RoleFunc calls duration histogram in seconds, by error
+//   - rbac.policy.roles and rbac.policy.methods: the number of roles and registered methods gauges
+type Metrics struct {
+	decisions  metric.Int64Counter
+	decision   metric.Float64Histogram
+	reauths    metric.Int64Counter
+	resolution metric.Float64Histogram
+
+	roles   int64
+	methods int64
+}
+
+// NewMetrics creates the Metrics instruments, to be used with grpc_rbac.WithMetrics
+func NewMetrics(opts ...Option) (*Metrics, error) {
+	o := newOptions(opts...)
+	meter := o.meterProvider.Meter(instrumentationName)
+	m := &Metrics{}
+	var err error
+	if m.decisions, err = meter.Int64Counter("rbac.decisions",
+		metric.WithDescription("Number of authorization decisions"),
+	); err != nil {
+		return nil, err
+	}
+	if m.decision, err = meter.Float64Histogram("rbac.decision.duration",
+		metric.WithDescription("Duration of the authorization decisions, including the roles resolution"),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, err
+	}
+	if m.reauths, err = meter.Int64Counter("rbac.reauthorizations",
+		metric.WithDescription("Number of streams re-authorizations"),
+	); err != nil {
+		return nil, err
+	}
+	if m.resolution, err = meter.Float64Histogram("rbac.role_resolution.duration",
+		metric.WithDescription("Duration of the roles resolutions"),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, err
+	}
+	roles, err := meter.Int64ObservableGauge("rbac.policy.roles", metric.WithDescription("Number of roles"))
+	if err != nil {
+		return nil, err
+	}
+	methods, err := meter.Int64ObservableGauge("rbac.policy.methods", metric.WithDescription("Number of registered methods"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(roles, atomic.LoadInt64(&m.roles))
+		o.ObserveInt64(methods, atomic.LoadInt64(&m.methods))
+		return nil
+	}, roles, methods); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Metrics) Decision(ctx context.Context, d grpc_rbac.Decision) {
+	method, outcome := MethodKey.String(d.Method), OutcomeKey.String(string(d.Outcome))
+	if d.Reauthorization {
+		m.reauths.Add(ctx, 1, metric.WithAttributes(method, outcome, RoleKey.String(d.Role)))
+		return
+	}
+	m.decisions.Add(ctx, 1, metric.WithAttributes(method, outcome, RoleKey.String(d.Role)))
+	m.decision.Record(ctx, d.Duration.Seconds(), metric.WithAttributes(method, outcome))
+}
+
+func (m *Metrics) RoleResolution(ctx context.Context, d time.Duration, err error) {
+	m.resolution.Record(ctx, d.Seconds(), metric.WithAttributes(ErrorKey.Bool(err != nil)))
+}
+
+func (m *Metrics) PolicySize(roles, methods int) {
+	atomic.StoreInt64(&m.roles, int64(roles))
+	atomic.StoreInt64(&m.methods, int64(methods))
+}
@@ -0,0 +1,190 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	grpc_rbac "go.linka.cloud/grpc-rbac"
+)
+
+const (
+	readMethod  = "/test.TestService/Read"
+	writeMethod = "/test.TestService/Write"
+)
+
+// newEngine returns an engine resolving the roles from the incoming "role" metadata, failing for the "fail" role,
+// where reader is allowed to call Read
+func newEngine(t *testing.T, opts ...grpc_rbac.Option) grpc_rbac.RBAC {
+	t.Helper()
+	r := grpc_rbac.New(append([]grpc_rbac.Option{grpc_rbac.WithRoleFunc(func(ctx context.Context) ([]grpc_rbac.Role, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var roles []grpc_rbac.Role
+		for _, v := range md.Get("role") {
+			if v == "fail" {
+				return nil, errors.New("store unreachable")
+			}
+			roles = append(roles, grpc_rbac.NewStdRole(v))
+		}
+		return roles, nil
+	})}, opts...)...)
+	if err := grpc_rbac.Grant(r, "reader", grpc_rbac.NewGRPCPermission("test.TestService", "Read")); err != nil {
+		t.Fatal(err)
+	}
+	r.Register(&grpc.ServiceDesc{
+		ServiceName: "test.TestService",
+		Methods:     []grpc.MethodDesc{{MethodName: "Read"}, {MethodName: "Write"}},
+	})
+	return r
+}
+
+// authorize authorizes the method call with the role
+func authorize(r grpc_rbac.RBAC, method, role string) error {
+	_, err := r.Authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs("role", role)), method)
+	return err
+}
+
+func TestMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	m, err := NewMetrics(WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newEngine(t, grpc_rbac.WithMetrics(m))
+	if err := authorize(r, readMethod, "reader"); err != nil {
+		t.Fatal(err)
+	}
+	if err := authorize(r, readMethod, "reader"); err != nil {
+		t.Fatal(err)
+	}
+	if err := authorize(r, writeMethod, "reader"); err == nil {
+		t.Fatal("expected permission denied")
+	}
+	if err := authorize(r, readMethod, "fail"); err == nil {
+		t.Fatal("expected roles resolution error")
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]metricdata.Aggregation)
+	for _, v := range rm.ScopeMetrics {
+		if v.Scope.Name != instrumentationName {
+			continue
+		}
+		for _, vv := range v.Metrics {
+			got[vv.Name] = vv.Data
+		}
+	}
+
+	decisions, ok := got["rbac.decisions"].(metricdata.Sum[int64])
+	if !ok {
+		t.Fatalf("missing rbac.decisions counter: %v", got)
+	}
+	counts := make(map[attribute.Set]int64)
+	for _, v := range decisions.DataPoints {
+		counts[v.Attributes] = v.Value
+	}
+	for _, v := range []struct {
+		method  string
+		outcome grpc_rbac.Outcome
+		role    string
+		want    int64
+	}{
+		{method: readMethod, outcome: grpc_rbac.OutcomeAllowed, role: "reader", want: 2},
+		{method: writeMethod, outcome: grpc_rbac.OutcomeDenied, want: 1},
+		{method: readMethod, outcome: grpc_rbac.OutcomeError, want: 1},
+	} {
+		set := attribute.NewSet(MethodKey.String(v.method), OutcomeKey.String(string(v.outcome)), RoleKey.String(v.role))
+		if counts[set] != v.want {
+			t.Errorf("%s %s decisions: got %d, want %d", v.method, v.outcome, counts[set], v.want)
+		}
+	}
+
+	duration, ok := got["rbac.decision.duration"].(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("missing rbac.decision.duration histogram: %v", got)
+	}
+	hist := make(map[attribute.Set]uint64)
+	for _, v := range duration.DataPoints {
+		hist[v.Attributes] = v.Count
+	}
+	if set := attribute.NewSet(MethodKey.String(readMethod), OutcomeKey.String(string(grpc_rbac.OutcomeAllowed))); hist[set] != 2 {
+		t.Errorf("allowed decisions duration count: got %d, want 2", hist[set])
+	}
+	if set := attribute.NewSet(MethodKey.String(writeMethod), OutcomeKey.String(string(grpc_rbac.OutcomeDenied))); hist[set] != 1 {
+		t.Errorf("denied decisions duration count: got %d, want 1", hist[set])
+	}
+
+	resolution, ok := got["rbac.role_resolution.duration"].(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("missing rbac.role_resolution.duration histogram: %v", got)
+	}
+	hist = make(map[attribute.Set]uint64)
+	for _, v := range resolution.DataPoints {
+		hist[v.Attributes] = v.Count
+	}
+	if ok, failed := hist[attribute.NewSet(ErrorKey.Bool(false))], hist[attribute.NewSet(ErrorKey.Bool(true))]; ok != 3 || failed != 1 {
+		t.Errorf("roles resolutions: got %d succeeded and %d failed, want 3 and 1", ok, failed)
+	}
+
+	for name, want := range map[string]int64{"rbac.policy.roles": 1, "rbac.policy.methods": 2} {
+		g, ok := got[name].(metricdata.Gauge[int64])
+		if !ok || len(g.DataPoints) != 1 || g.DataPoints[0].Value != want {
+			t.Errorf("%s: got %v, want %d", name, got[name], want)
+		}
+	}
+}
+
+func TestReauthorizations(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	m, err := NewMetrics(WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		m.Decision(context.Background(), grpc_rbac.Decision{Method: readMethod, Outcome: grpc_rbac.OutcomeAllowed, Role: "reader", Reauthorization: true})
+	}
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]metricdata.Aggregation)
+	for _, v := range rm.ScopeMetrics {
+		for _, vv := range v.Metrics {
+			got[vv.Name] = vv.Data
+		}
+	}
+	reauths, ok := got["rbac.reauthorizations"].(metricdata.Sum[int64])
+	if !ok || len(reauths.DataPoints) != 1 || reauths.DataPoints[0].Value != 2 {
+		t.Fatalf("rbac.reauthorizations: got %v, want 2", got["rbac.reauthorizations"])
+	}
+	want := attribute.NewSet(MethodKey.String(readMethod), OutcomeKey.String(string(grpc_rbac.OutcomeAllowed)), RoleKey.String("reader"))
+	if !reauths.DataPoints[0].Attributes.Equals(&want) {
+		t.Errorf("got attributes %v, want %v", reauths.DataPoints[0].Attributes, want)
+	}
+	if _, ok := got["rbac.decisions"]; ok {
+		t.Error("the re-authorizations should not be recorded as decisions")
+	}
+}
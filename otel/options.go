@@ -0,0 +1,60 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otel
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type options struct {
+	meterProvider  metric.MeterProvider
+	tracerProvider trace.TracerProvider
+	currentSpan    bool
+}
+
+func newOptions(opts ...Option) options {
+	o := options{meterProvider: otel.GetMeterProvider(), tracerProvider: otel.GetTracerProvider()}
+	for _, v := range opts {
+		v(&o)
+	}
+	return o
+}
+
+// Option configures the Metrics and the Tracer
+type Option func(o *options)
+
+// WithMeterProvider sets the Metrics MeterProvider, the global one by default
+func WithMeterProvider(p metric.MeterProvider) Option {
+	return func(o *options) {
+		o.meterProvider = p
+	}
+}
+
+// WithTracerProvider sets the Tracer TracerProvider, the global one by default
+func WithTracerProvider(p trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = p
+	}
+}
+
+// WithCurrentSpan makes the Tracer add the authorization attributes to the current span
+// instead of creating an rbac.authorize span
+func WithCurrentSpan() Option {
+	return func(o *options) {
+		o.currentSpan = true
+	}
+}
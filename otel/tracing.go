@@ -0,0 +1,108 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	grpc_rbac "go.linka.cloud/grpc-rbac"
+)
+
+const (
+	// AuthorizeSpanName is the name of the authorization span
+	AuthorizeSpanName = "rbac.authorize"
+	// ReauthorizeSpanName is the name of the streams re-authorization spans,
+	// and of the current span events with WithCurrentSpan
+	ReauthorizeSpanName = "rbac.reauthorize"
+	// ResolveRolesSpanName is the name of the roles resolution span
+	ResolveRolesSpanName = "rbac.resolve_roles"
+)
+
+var _ grpc_rbac.Tracer = (*Tracer)(nil)
+
+// Tracer traces the authorization in an rbac.authorize span, or in the current span with WithCurrentSpan,
+// with the method, the caller roles, the outcome and the matched role attributes.
+// The streams re-authorizations are traced in rbac.reauthorize spans, or as rbac.reauthorize events of the
+// current span with WithCurrentSpan. The roles resolution is traced in an rbac.resolve_roles child span.
+type Tracer struct {
+	tracer      trace.Tracer
+	currentSpan bool
+}
+
+// NewTracer returns a Tracer, to be used with grpc_rbac.WithTracer
+func NewTracer(opts ...Option) *Tracer {
+	o := newOptions(opts...)
+	return &Tracer{tracer: o.tracerProvider.Tracer(instrumentationName), currentSpan: o.currentSpan}
+}
+
+func (t *Tracer) Authorize(ctx context.Context, method string) (context.Context, func(d grpc_rbac.Decision)) {
+	var span trace.Span
+	if t.currentSpan {
+		span = trace.SpanFromContext(ctx)
+		span.SetAttributes(MethodKey.String(method))
+	} else {
+		ctx, span = t.tracer.Start(ctx, AuthorizeSpanName, trace.WithAttributes(MethodKey.String(method)))
+	}
+	return ctx, func(d grpc_rbac.Decision) {
+		attrs := []attribute.KeyValue{
+			RolesKey.StringSlice(d.Roles),
+			OutcomeKey.String(string(d.Outcome)),
+			RoleKey.String(d.Role),
+		}
+		if d.Reauthorization && t.currentSpan {
+			// the current span holds the call decision
+			span.AddEvent(ReauthorizeSpanName, trace.WithAttributes(attrs...))
+			return
+		}
+		if d.Reauthorization {
+			span.SetName(ReauthorizeSpanName)
+		}
+		span.SetAttributes(attrs...)
+		if d.Outcome == grpc_rbac.OutcomeError {
+			span.RecordError(d.Err)
+			if !t.currentSpan {
+				span.SetStatus(codes.Error, d.Err.Error())
+			}
+		}
+		if !t.currentSpan {
+			span.End()
+		}
+	}
+}
+
+func (t *Tracer) ResolveRoles(ctx context.Context) (context.Context, func(roles []grpc_rbac.Role, err error)) {
+	ctx, span := t.tracer.Start(ctx, ResolveRolesSpanName)
+	return ctx, func(roles []grpc_rbac.Role, err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else {
+			span.SetAttributes(RolesKey.StringSlice(ids(roles)))
+		}
+		span.End()
+	}
+}
+
+func ids(roles []grpc_rbac.Role) []string {
+	out := make([]string, 0, len(roles))
+	for _, v := range roles {
+		out = append(out, v.ID())
+	}
+	return out
+}
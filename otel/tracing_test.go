@@ -0,0 +1,160 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc/metadata"
+
+	grpc_rbac "go.linka.cloud/grpc-rbac"
+)
+
+func TestTracer(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		role    string
+		attrs   []attribute.KeyValue
+		status  codes.Code
+		resolve codes.Code
+	}{
+		{
+			name:   "allowed",
+			method: readMethod,
+			role:   "reader",
+			attrs: []attribute.KeyValue{
+				MethodKey.String(readMethod),
+				RolesKey.StringSlice([]string{"reader"}),
+				OutcomeKey.String(string(grpc_rbac.OutcomeAllowed)),
+				RoleKey.String("reader"),
+			},
+		},
+		{
+			name:   "denied",
+			method: writeMethod,
+			role:   "reader",
+			attrs: []attribute.KeyValue{
+				MethodKey.String(writeMethod),
+				RolesKey.StringSlice([]string{"reader"}),
+				OutcomeKey.String(string(grpc_rbac.OutcomeDenied)),
+				RoleKey.String(""),
+			},
+		},
+		{
+			name:   "error",
+			method: readMethod,
+			role:   "fail",
+			attrs: []attribute.KeyValue{
+				MethodKey.String(readMethod),
+				RolesKey.StringSlice([]string{}),
+				OutcomeKey.String(string(grpc_rbac.OutcomeError)),
+				RoleKey.String(""),
+			},
+			status:  codes.Error,
+			resolve: codes.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := tracetest.NewSpanRecorder()
+			r := newEngine(t, grpc_rbac.WithTracer(NewTracer(WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))))))
+			_ = authorize(r, tt.method, tt.role)
+			spans := rec.Ended()
+			if len(spans) != 2 {
+				t.Fatalf("expected 2 spans, got %d", len(spans))
+			}
+			resolve, authz := spans[0], spans[1]
+			if authz.Name() != AuthorizeSpanName || resolve.Name() != ResolveRolesSpanName {
+				t.Fatalf("unexpected spans %s and %s", resolve.Name(), authz.Name())
+			}
+			if resolve.Parent().SpanID() != authz.SpanContext().SpanID() {
+				t.Error("the roles resolution span is not a child of the authorization span")
+			}
+			if !reflect.DeepEqual(authz.Attributes(), tt.attrs) {
+				t.Errorf("got attributes %v, want %v", authz.Attributes(), tt.attrs)
+			}
+			if authz.Status().Code != tt.status {
+				t.Errorf("got status %v, want %v", authz.Status(), tt.status)
+			}
+			if resolve.Status().Code != tt.resolve {
+				t.Errorf("got roles resolution status %v, want %v", resolve.Status(), tt.resolve)
+			}
+			if tt.status == codes.Error && len(authz.Events()) == 0 {
+				t.Error("missing error event")
+			}
+		})
+	}
+}
+
+func TestTracerCurrentSpan(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	r := newEngine(t, grpc_rbac.WithTracer(NewTracer(WithTracerProvider(tp), WithCurrentSpan())))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "call")
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("role", "reader"))
+	if _, err := r.Authorize(ctx, readMethod); err != nil {
+		t.Fatal(err)
+	}
+	span.End()
+	spans := rec.Ended()
+	if len(spans) != 2 || spans[1].Name() != "call" {
+		t.Fatalf("unexpected spans %v", spans)
+	}
+	want := []attribute.KeyValue{
+		MethodKey.String(readMethod),
+		RolesKey.StringSlice([]string{"reader"}),
+		OutcomeKey.String(string(grpc_rbac.OutcomeAllowed)),
+		RoleKey.String("reader"),
+	}
+	if !reflect.DeepEqual(spans[1].Attributes(), want) {
+		t.Errorf("got attributes %v, want %v", spans[1].Attributes(), want)
+	}
+}
+
+func TestTracerReauthorization(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	d := grpc_rbac.Decision{Method: readMethod, Outcome: grpc_rbac.OutcomeAllowed, Role: "reader", Roles: []string{"reader"}, Reauthorization: true}
+
+	_, end := NewTracer(WithTracerProvider(tp)).Authorize(context.Background(), readMethod)
+	end(d)
+	spans := rec.Ended()
+	if len(spans) != 1 || spans[0].Name() != ReauthorizeSpanName {
+		t.Fatalf("unexpected spans %v", spans)
+	}
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "call")
+	_, end = NewTracer(WithTracerProvider(tp), WithCurrentSpan()).Authorize(ctx, readMethod)
+	end(d)
+	span.End()
+	spans = rec.Ended()
+	if len(spans) != 2 || spans[1].Name() != "call" {
+		t.Fatalf("unexpected spans %v", spans)
+	}
+	if len(spans[1].Events()) != 1 || spans[1].Events()[0].Name != ReauthorizeSpanName {
+		t.Fatalf("expected an %s event, got %v", ReauthorizeSpanName, spans[1].Events())
+	}
+	want := []attribute.KeyValue{MethodKey.String(readMethod)}
+	if !reflect.DeepEqual(spans[1].Attributes(), want) {
+		t.Errorf("the re-authorization should not override the call attributes, got %v", spans[1].Attributes())
+	}
+}
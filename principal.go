@@ -0,0 +1,112 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// PrincipalMetadataKey is the metadata key holding the signed principal passed downstream
+const PrincipalMetadataKey = "x-rbac-principal"
+
+// DefaultPrincipalTTL is the default validity duration of the signed principals
+const DefaultPrincipalTTL = 30 * time.Second
+
+// Principal is the caller identity, as authorized by an upstream service, passed downstream in signed metadata
+type Principal struct {
+	// Roles are the caller roles ids
+	Roles []string `json:"roles"`
+	// Method is the full method the principal was issued for, the principal is valid for any method if empty
+	Method string `json:"method,omitempty"`
+	// Expires is the principal expiration time, as unix seconds
+	Expires int64 `json:"exp"`
+}
+
+// SignPrincipal returns the principal token signed with the key using HMAC-SHA256
+func SignPrincipal(key []byte, p Principal) (string, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(sign(key, payload)), nil
+}
+
+// VerifyPrincipal returns the principal of the token if it is signed with the key and not expired,
+// an ErrUnauthenticated error otherwise
+func VerifyPrincipal(key []byte, token string) (Principal, error) {
+	var p Principal
+	i := strings.LastIndex(token, ".")
+	if i == -1 {
+		return p, fmt.Errorf("%w: malformed principal", ErrUnauthenticated)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil || !hmac.Equal(sig, sign(key, token[:i])) {
+		return p, fmt.Errorf("%w: invalid principal signature", ErrUnauthenticated)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token[:i])
+	if err != nil {
+		return p, fmt.Errorf("%w: malformed principal: %v", ErrUnauthenticated, err)
+	}
+	if err := json.Unmarshal(b, &p); err != nil {
+		return p, fmt.Errorf("%w: malformed principal: %v", ErrUnauthenticated, err)
+	}
+	if time.Now().Unix() >= p.Expires {
+		return p, fmt.Errorf("%w: principal expired", ErrUnauthenticated)
+	}
+	return p, nil
+}
+
+func sign(key []byte, payload string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+// PrincipalRoleFunc returns a RoleFunc reading the caller roles from the signed principal in the
+// PrincipalMetadataKey incoming metadata, e.g. for the backends of the proxy, see RBAC.Proxy.
+// The calls without principal have no roles. The principals with an invalid signature, expired
+// or issued for another method are rejected as unauthenticated.
+func PrincipalRoleFunc(key []byte) RoleFunc {
+	return func(ctx context.Context) ([]Role, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		v := md.Get(PrincipalMetadataKey)
+		if len(v) == 0 {
+			return nil, nil
+		}
+		p, err := VerifyPrincipal(key, v[0])
+		if err != nil {
+			return nil, err
+		}
+		if m, ok := grpc.Method(ctx); ok && p.Method != "" && p.Method != m {
+			return nil, fmt.Errorf("%w: principal issued for %s", ErrUnauthenticated, p.Method)
+		}
+		var roles []Role
+		for _, v := range p.Roles {
+			roles = append(roles, NewStdRole(v))
+		}
+		return roles, nil
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+)
+
+var testKey = []byte("test-key")
+
+// withPrincipal returns the outgoing context sending the token in the PrincipalMetadataKey metadata
+func withPrincipal(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, PrincipalMetadataKey, token)
+}
+
+// mustSign returns the principal signed with the key
+func mustSign(t *testing.T, key []byte, p Principal) string {
+	t.Helper()
+	token, err := SignPrincipal(key, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestVerifyPrincipal(t *testing.T) {
+	exp := time.Now().Add(time.Minute).Unix()
+	valid := mustSign(t, testKey, Principal{Roles: []string{"reader"}, Method: readMethod, Expires: exp})
+	forged := mustSign(t, testKey, Principal{Roles: []string{"admin"}, Expires: exp})
+	notJSON := base64.RawURLEncoding.EncodeToString([]byte("not json"))
+	tests := []struct {
+		name  string
+		key   []byte
+		token string
+		want  Principal
+		err   bool
+	}{
+		{
+			name:  "valid",
+			key:   testKey,
+			token: valid,
+			want:  Principal{Roles: []string{"reader"}, Method: readMethod, Expires: exp},
+		},
+		{
+			name:  "wrong key",
+			key:   []byte("other-key"),
+			token: valid,
+			err:   true,
+		},
+		{
+			name:  "tampered payload",
+			key:   testKey,
+			token: forged[:strings.LastIndex(forged, ".")] + valid[strings.LastIndex(valid, "."):],
+			err:   true,
+		},
+		{
+			name:  "expired",
+			key:   testKey,
+			token: mustSign(t, testKey, Principal{Roles: []string{"reader"}, Expires: time.Now().Add(-time.Second).Unix()}),
+			err:   true,
+		},
+		{
+			name:  "missing signature",
+			key:   testKey,
+			token: "principal",
+			err:   true,
+		},
+		{
+			name:  "malformed signature",
+			key:   testKey,
+			token: "principal.!",
+			err:   true,
+		},
+		{
+			name:  "malformed payload",
+			key:   testKey,
+			token: notJSON + "." + base64.RawURLEncoding.EncodeToString(sign(testKey, notJSON)),
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := VerifyPrincipal(tt.key, tt.token)
+			if tt.err {
+				if !errors.Is(err, ErrUnauthenticated) {
+					t.Fatalf("expected ErrUnauthenticated, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Method != tt.want.Method || p.Expires != tt.want.Expires || len(p.Roles) != len(tt.want.Roles) || p.Roles[0] != tt.want.Roles[0] {
+				t.Fatalf("expected %+v, got %+v", tt.want, p)
+			}
+		})
+	}
+}
+
+func TestPrincipalRoleFunc(t *testing.T) {
+	c := serve(t, newTestRBAC(t, WithRoleFunc(PrincipalRoleFunc(testKey))))
+	exp := time.Now().Add(time.Minute).Unix()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		code codes.Code
+	}{
+		{
+			name: "no principal",
+			ctx:  context.Background(),
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "principal for the method",
+			ctx:  withPrincipal(context.Background(), mustSign(t, testKey, Principal{Roles: []string{"reader"}, Method: readMethod, Expires: exp})),
+			code: codes.OK,
+		},
+		{
+			name: "principal for any method",
+			ctx:  withPrincipal(context.Background(), mustSign(t, testKey, Principal{Roles: []string{"reader"}, Expires: exp})),
+			code: codes.OK,
+		},
+		{
+			name: "principal without the role",
+			ctx:  withPrincipal(context.Background(), mustSign(t, testKey, Principal{Roles: []string{"writer"}, Method: readMethod, Expires: exp})),
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "principal for another method",
+			ctx:  withPrincipal(context.Background(), mustSign(t, testKey, Principal{Roles: []string{"admin"}, Method: writeMethod, Expires: exp})),
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "principal signed with another key",
+			ctx:  withPrincipal(context.Background(), mustSign(t, []byte("other-key"), Principal{Roles: []string{"reader"}, Method: readMethod, Expires: exp})),
+			code: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Read(tt.ctx, &testpb.Request{})
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("expected %v, got %v: %v", tt.code, got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+module go.linka.cloud/grpc-rbac/prometheus
+
+go 1.16
+
+require (
+	github.com/prometheus/client_golang v1.14.0
+	github.com/prometheus/client_model v0.3.0
+	go.linka.cloud/grpc-rbac v0.0.0-00010101000000-000000000000
+	golang.org/x/net v0.11.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
+	google.golang.org/grpc v1.54.0
+)
+
+replace go.linka.cloud/grpc-rbac => ./..
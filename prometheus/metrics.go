@@ -0,0 +1,146 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prometheus provides a Prometheus collector implementation of the grpc_rbac.Metrics.
+package prometheus
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	grpc_rbac "go.linka.cloud/grpc-rbac"
+)
+
+var (
+	_ grpc_rbac.Metrics = (*Metrics)(nil)
+	_ prom.Collector    = (*Metrics)(nil)
+)
+
+// Metrics is a prometheus.Collector recording the authorization decisions:
+//   - grpc_rbac_decisions_total: the decisions counter, by method, outcome and matched role
+//   - grpc_rbac_decision_duration_seconds: the decisions duration histogram, by method and outcome
+//   - grpc_rbac_reauthorizations_total: the streams re-authorizations counter, by method, outcome and matched role
+//   - grpc_rbac_role_resolution_duration_seconds: the RoleFunc calls duration histogram, by error
+//   - grpc_rbac_policy_roles and grpc_rbac_policy_methods: the number of roles and registered methods gauges
+type Metrics struct {
+	decisions  *prom.CounterVec
+	decision   *prom.HistogramVec
+	reauths    *prom.CounterVec
+	resolution *prom.HistogramVec
+	roles      prom.Gauge
+	methods    prom.Gauge
+}
+
+type options struct {
+	namespace string
+	buckets   []float64
+}
+
+// Option configures the Metrics
+type Option func(o *options)
+
+// WithNamespace sets the metrics namespace, grpc_rbac by default
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithBuckets sets the durations histograms buckets, prometheus.DefBuckets by default
+func WithBuckets(buckets ...float64) Option {
+	return func(o *options) {
+		o.buckets = buckets
+	}
+}
+
+// NewMetrics returns the Metrics, to be used with grpc_rbac.WithMetrics and registered in a prometheus.Registerer
+func NewMetrics(opts ...Option) *Metrics {
+	o := options{namespace: "grpc_rbac", buckets: prom.DefBuckets}
+	for _, v := range opts {
+		v(&o)
+	}
+	return &Metrics{
+		decisions: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "decisions_total",
+			Help:      "Number of authorization decisions.",
+		}, []string{"method", "outcome", "role"}),
+		decision: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: o.namespace,
+			Name:      "decision_duration_seconds",
+			Help:      "Duration of the authorization decisions, including the roles resolution.",
+			Buckets:   o.buckets,
+		}, []string{"method", "outcome"}),
+		reauths: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "reauthorizations_total",
+			Help:      "Number of streams re-authorizations.",
+		}, []string{"method", "outcome", "role"}),
+		resolution: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: o.namespace,
+			Name:      "role_resolution_duration_seconds",
+			Help:      "Duration of the roles resolutions.",
+			Buckets:   o.buckets,
+		}, []string{"error"}),
+		roles: prom.NewGauge(prom.GaugeOpts{
+			Namespace: o.namespace,
+			Name:      "policy_roles",
+			Help:      "Number of roles.",
+		}),
+		methods: prom.NewGauge(prom.GaugeOpts{
+			Namespace: o.namespace,
+			Name:      "policy_methods",
+			Help:      "Number of registered methods.",
+		}),
+	}
+}
+
+func (m *Metrics) Describe(ch chan<- *prom.Desc) {
+	m.decisions.Describe(ch)
+	m.decision.Describe(ch)
+	m.reauths.Describe(ch)
+	m.resolution.Describe(ch)
+	m.roles.Describe(ch)
+	m.methods.Describe(ch)
+}
+
+func (m *Metrics) Collect(ch chan<- prom.Metric) {
+	m.decisions.Collect(ch)
+	m.decision.Collect(ch)
+	m.reauths.Collect(ch)
+	m.resolution.Collect(ch)
+	m.roles.Collect(ch)
+	m.methods.Collect(ch)
+}
+
+func (m *Metrics) Decision(_ context.Context, d grpc_rbac.Decision) {
+	if d.Reauthorization {
+		m.reauths.WithLabelValues(d.Method, string(d.Outcome), d.Role).Inc()
+		return
+	}
+	m.decisions.WithLabelValues(d.Method, string(d.Outcome), d.Role).Inc()
+	m.decision.WithLabelValues(d.Method, string(d.Outcome)).Observe(d.Duration.Seconds())
+}
+
+func (m *Metrics) RoleResolution(_ context.Context, d time.Duration, err error) {
+	m.resolution.WithLabelValues(strconv.FormatBool(err != nil)).Observe(d.Seconds())
+}
+
+func (m *Metrics) PolicySize(roles, methods int) {
+	m.roles.Set(float64(roles))
+	m.methods.Set(float64(methods))
+}
@@ -0,0 +1,154 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	grpc_rbac "go.linka.cloud/grpc-rbac"
+)
+
+const (
+	readMethod  = "/test.TestService/Read"
+	writeMethod = "/test.TestService/Write"
+)
+
+// newEngine returns an engine resolving the roles from the incoming "role" metadata, failing for the "fail" role,
+// where reader is allowed to call Read
+func newEngine(t *testing.T, opts ...grpc_rbac.Option) grpc_rbac.RBAC {
+	t.Helper()
+	r := grpc_rbac.New(append([]grpc_rbac.Option{grpc_rbac.WithRoleFunc(func(ctx context.Context) ([]grpc_rbac.Role, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var roles []grpc_rbac.Role
+		for _, v := range md.Get("role") {
+			if v == "fail" {
+				return nil, errors.New("store unreachable")
+			}
+			roles = append(roles, grpc_rbac.NewStdRole(v))
+		}
+		return roles, nil
+	})}, opts...)...)
+	if err := grpc_rbac.Grant(r, "reader", grpc_rbac.NewGRPCPermission("test.TestService", "Read")); err != nil {
+		t.Fatal(err)
+	}
+	r.Register(&grpc.ServiceDesc{
+		ServiceName: "test.TestService",
+		Methods:     []grpc.MethodDesc{{MethodName: "Read"}, {MethodName: "Write"}},
+	})
+	return r
+}
+
+// authorize authorizes the method call with the role
+func authorize(r grpc_rbac.RBAC, method, role string) error {
+	_, err := r.Authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs("role", role)), method)
+	return err
+}
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics(WithNamespace("test"))
+	reg := prom.NewPedanticRegistry()
+	if err := reg.Register(m); err != nil {
+		t.Fatal(err)
+	}
+	r := newEngine(t, grpc_rbac.WithMetrics(m))
+	if err := authorize(r, readMethod, "reader"); err != nil {
+		t.Fatal(err)
+	}
+	if err := authorize(r, readMethod, "reader"); err != nil {
+		t.Fatal(err)
+	}
+	if err := authorize(r, writeMethod, "reader"); err == nil {
+		t.Fatal("expected permission denied")
+	}
+	if err := authorize(r, readMethod, "fail"); err == nil {
+		t.Fatal("expected roles resolution error")
+	}
+
+	for _, v := range []struct {
+		labels []string
+		want   float64
+	}{
+		{labels: []string{readMethod, string(grpc_rbac.OutcomeAllowed), "reader"}, want: 2},
+		{labels: []string{writeMethod, string(grpc_rbac.OutcomeDenied), ""}, want: 1},
+		{labels: []string{readMethod, string(grpc_rbac.OutcomeError), ""}, want: 1},
+	} {
+		if got := testutil.ToFloat64(m.decisions.WithLabelValues(v.labels...)); got != v.want {
+			t.Errorf("%v decisions: got %v, want %v", v.labels, got, v.want)
+		}
+	}
+	if got := testutil.ToFloat64(m.roles); got != 1 {
+		t.Errorf("policy roles: got %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.methods); got != 2 {
+		t.Errorf("policy methods: got %v, want 2", got)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]uint64)
+	for _, f := range families {
+		for _, v := range f.GetMetric() {
+			if v.GetHistogram() != nil {
+				counts[f.GetName()+labels(v)] = v.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	for k, want := range map[string]uint64{
+		"test_decision_duration_seconds{method=" + readMethod + ",outcome=allowed}": 2,
+		"test_decision_duration_seconds{method=" + writeMethod + ",outcome=denied}": 1,
+		"test_decision_duration_seconds{method=" + readMethod + ",outcome=error}":   1,
+		"test_role_resolution_duration_seconds{error=false}":                        3,
+		"test_role_resolution_duration_seconds{error=true}":                         1,
+	} {
+		if counts[k] != want {
+			t.Errorf("%s: got %d samples, want %d", k, counts[k], want)
+		}
+	}
+}
+
+// labels returns the metric labels as {name=value,...}
+func labels(m *dto.Metric) string {
+	s := "{"
+	for i, v := range m.GetLabel() {
+		if i != 0 {
+			s += ","
+		}
+		s += v.GetName() + "=" + v.GetValue()
+	}
+	return s + "}"
+}
+
+func TestReauthorizations(t *testing.T) {
+	m := NewMetrics()
+	for i := 0; i < 2; i++ {
+		m.Decision(context.Background(), grpc_rbac.Decision{Method: readMethod, Outcome: grpc_rbac.OutcomeAllowed, Role: "reader", Reauthorization: true})
+	}
+	if got := testutil.ToFloat64(m.reauths.WithLabelValues(readMethod, string(grpc_rbac.OutcomeAllowed), "reader")); got != 2 {
+		t.Errorf("re-authorizations: got %v, want 2", got)
+	}
+	if n := testutil.CollectAndCount(m.decisions) + testutil.CollectAndCount(m.decision); n != 0 {
+		t.Errorf("the re-authorizations should not be recorded as decisions, got %d series", n)
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ProxyOption configures the proxy handler, see RBAC.Proxy
+type ProxyOption func(p *proxy)
+
+// WithProxySigningKey sets the key signing the principal passed to the backend in the PrincipalMetadataKey
+// metadata, see PrincipalRoleFunc. The principal is not sent if no key is set.
+func WithProxySigningKey(key []byte) ProxyOption {
+	return func(p *proxy) {
+		p.key = key
+	}
+}
+
+// WithProxyPrincipalTTL sets the validity duration of the principal passed to the backend, DefaultPrincipalTTL by default
+func WithProxyPrincipalTTL(ttl time.Duration) ProxyOption {
+	return func(p *proxy) {
+		p.ttl = ttl
+	}
+}
+
+// WithProxyDescriptors sets the files used to decode the proxied messages in order to enforce the fields rules:
+// the requests setting fields the caller is not allowed to write are denied and the responses fields the caller
+// is not allowed to read are cleared. The messages of the methods not found in the files are forwarded as is.
+func WithProxyDescriptors(files *protoregistry.Files) ProxyOption {
+	return func(p *proxy) {
+		p.files = files
+	}
+}
+
+// ProxyCodec returns the codec the proxy server must be created with, using grpc.ForceServerCodec.
+// It passes the proxied frames as is and uses the proto codec for the other messages,
+// so the server can still serve its own services.
+func ProxyCodec() encoding.Codec {
+	return proxyCodec{}
+}
+
+// frame is a raw proxied message
+type frame struct {
+	payload []byte
+}
+
+type proxyCodec struct{}
+
+func (proxyCodec) Marshal(v interface{}) ([]byte, error) {
+	if f, ok := v.(*frame); ok {
+		return f.payload, nil
+	}
+	return encoding.GetCodec("proto").Marshal(v)
+}
+
+func (proxyCodec) Unmarshal(data []byte, v interface{}) error {
+	if f, ok := v.(*frame); ok {
+		f.payload = append(f.payload[:0], data...)
+		return nil
+	}
+	return encoding.GetCodec("proto").Unmarshal(data, v)
+}
+
+func (proxyCodec) Name() string {
+	return "proto"
+}
+
+type proxy struct {
+	r       *rbac
+	backend grpc.ClientConnInterface
+	key     []byte
+	ttl     time.Duration
+	files   *protoregistry.Files
+}
+
+func (r *rbac) Proxy(backend grpc.ClientConnInterface, opts ...ProxyOption) grpc.StreamHandler {
+	p := &proxy{r: r, backend: backend, ttl: DefaultPrincipalTTL}
+	for _, v := range opts {
+		v(p)
+	}
+	return p.handle
+}
+
+func (p *proxy) handle(_ interface{}, ss grpc.ServerStream) error {
+	method, ok := grpc.MethodFromServerStream(ss)
+	if !ok {
+		return status.Error(codes.Internal, "grpc rbac: missing method in proxied stream")
+	}
+	ctx, err := p.r.Authorize(ss.Context(), method)
+	if err != nil {
+		return err
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+	delete(md, ":authority")
+	delete(md, PrincipalMetadataKey)
+	if p.key != nil {
+		roles, _ := RolesFromContext(ctx)
+		token, err := SignPrincipal(p.key, Principal{Roles: ids(roles), Method: method, Expires: time.Now().Add(p.ttl).Unix()})
+		if err != nil {
+			return status.Errorf(codes.Internal, "grpc rbac: sign principal: %v", err)
+		}
+		md.Set(PrincipalMetadataKey, token)
+	}
+	in, out := p.messages(method)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cs, err := p.backend.NewStream(metadata.NewOutgoingContext(ctx, md), &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, method, grpc.ForceCodec(proxyCodec{}))
+	if err != nil {
+		return err
+	}
+	reqs := make(chan error, 1)
+	go func() {
+		reqs <- p.forwardRequests(ctx, ss, cs, in)
+	}()
+	res := make(chan error, 1)
+	go func() {
+		res <- p.forwardResponses(ctx, cs, ss, out)
+	}()
+	for {
+		select {
+		case err := <-reqs:
+			if err != io.EOF {
+				return err
+			}
+			if err := cs.CloseSend(); err != nil {
+				return err
+			}
+		case err := <-res:
+			ss.SetTrailer(cs.Trailer())
+			if err != io.EOF {
+				return err
+			}
+			return nil
+		}
+	}
+}
+
+// forwardRequests forwards the client messages to the backend until the client closes its side of the stream
+func (p *proxy) forwardRequests(ctx context.Context, ss grpc.ServerStream, cs grpc.ClientStream, md protoreflect.MessageDescriptor) error {
+	for {
+		f := &frame{}
+		if err := ss.RecvMsg(f); err != nil {
+			return err
+		}
+		if md != nil && p.r.hasRules(&p.r.protected, md, writeRoles) {
+			m := dynamicpb.NewMessage(md)
+			if err := proto.Unmarshal(f.payload, m); err != nil {
+				return status.Errorf(codes.InvalidArgument, "grpc rbac: decode %s: %v", md.FullName(), err)
+			}
+			if err := p.r.CheckFields(ctx, m); err != nil {
+				return err
+			}
+		}
+		if err := cs.SendMsg(f); err != nil {
+			return err
+		}
+	}
+}
+
+// forwardResponses forwards the backend headers and messages to the client until the backend ends the stream.
+// The headers are forwarded as soon as they are received, so they are not lost if the backend fails before sending a message.
+func (p *proxy) forwardResponses(ctx context.Context, cs grpc.ClientStream, ss grpc.ServerStream, md protoreflect.MessageDescriptor) error {
+	// the headers error is the stream one, returned by RecvMsg
+	if h, err := cs.Header(); err == nil && h != nil {
+		if err := ss.SendHeader(h); err != nil {
+			return err
+		}
+	}
+	for {
+		f := &frame{}
+		if err := cs.RecvMsg(f); err != nil {
+			return err
+		}
+		if md != nil && p.r.hasRules(&p.r.masked, md, readRoles) {
+			m := dynamicpb.NewMessage(md)
+			if err := proto.Unmarshal(f.payload, m); err != nil {
+				return status.Errorf(codes.Internal, "grpc rbac: decode %s: %v", md.FullName(), err)
+			}
+			b, err := proto.Marshal(p.r.MaskFields(ctx, m).(proto.Message))
+			if err != nil {
+				return status.Errorf(codes.Internal, "grpc rbac: encode %s: %v", md.FullName(), err)
+			}
+			f.payload = b
+		}
+		if err := ss.SendMsg(f); err != nil {
+			return err
+		}
+	}
+}
+
+// messages returns the method input and output descriptors, if found in the proxy files
+func (p *proxy) messages(fullMethod string) (in, out protoreflect.MessageDescriptor) {
+	if p.files == nil {
+		return nil, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(fullMethod, "/"), "/")
+	if len(parts) != 2 {
+		return nil, nil
+	}
+	d, err := p.files.FindDescriptorByName(protoreflect.FullName(parts[0]))
+	if err != nil {
+		return nil, nil
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, nil
+	}
+	m := sd.Methods().ByName(protoreflect.Name(parts[1]))
+	if m == nil {
+		return nil, nil
+	}
+	return m.Input(), m.Output()
+}
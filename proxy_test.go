@@ -0,0 +1,311 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+)
+
+// backend records the roles of the principals it receives
+type backend struct {
+	mu    sync.Mutex
+	roles []string
+}
+
+func (b *backend) intercept(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	roles, err := PrincipalRoleFunc(testKey)(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	b.roles = ids(roles)
+	b.mu.Unlock()
+	return handler(ctx, req)
+}
+
+// stream sends the x-backend header, then fails the stream if the "fail" metadata is set
+func (b *backend) stream(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := ss.SendHeader(metadata.Pairs("x-backend", "value")); err != nil {
+		return err
+	}
+	if md, _ := metadata.FromIncomingContext(ss.Context()); len(md.Get("fail")) != 0 {
+		return status.Error(codes.Internal, "backend failure")
+	}
+	return handler(srv, ss)
+}
+
+func (b *backend) received() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.roles
+}
+
+// serveProxy serves the engine proxy in front of the backend, without authorization, and returns a client connected to it
+func serveProxy(t *testing.T, b *backend, opts ...ProxyOption) testpb.TestServiceClient {
+	t.Helper()
+	cc := dialServer(t, grpc.NewServer(grpc.UnaryInterceptor(b.intercept), grpc.StreamInterceptor(b.stream)))
+	return testpb.NewTestServiceClient(listen(t, grpc.NewServer(
+		grpc.ForceServerCodec(ProxyCodec()),
+		grpc.UnknownServiceHandler(newTestRBAC(t).Proxy(cc, opts...)),
+	)))
+}
+
+func TestProxy(t *testing.T) {
+	b := &backend{}
+	c := serveProxy(t, b, WithProxySigningKey(testKey))
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		call  func(ctx context.Context, c testpb.TestServiceClient) error
+		code  codes.Code
+		roles []string
+	}{
+		{
+			name:  "allowed",
+			ctx:   withRoles(context.Background(), "reader"),
+			call:  read,
+			roles: []string{"reader"},
+		},
+		{
+			name: "denied",
+			ctx:  withRoles(context.Background(), "reader"),
+			call: write,
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "unauthenticated",
+			ctx:  context.Background(),
+			call: read,
+			code: codes.Unauthenticated,
+		},
+		{
+			name:  "public",
+			ctx:   context.Background(),
+			call:  public,
+			roles: []string{},
+		},
+		{
+			name:  "forged principal",
+			ctx:   withPrincipal(withRoles(context.Background(), "reader"), mustSign(t, testKey, Principal{Roles: []string{"admin"}, Expires: 1 << 40})),
+			call:  read,
+			roles: []string{"reader"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b.mu.Lock()
+			b.roles = nil
+			b.mu.Unlock()
+			err := tt.call(tt.ctx, c)
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("expected %v, got %v: %v", tt.code, got, err)
+			}
+			if tt.roles == nil {
+				return
+			}
+			got := b.received()
+			if len(got) != len(tt.roles) {
+				t.Fatalf("expected backend roles %v, got %v", tt.roles, got)
+			}
+			for i := range got {
+				if got[i] != tt.roles[i] {
+					t.Fatalf("expected backend roles %v, got %v", tt.roles, got)
+				}
+			}
+		})
+	}
+}
+
+func TestProxyWithoutSigningKey(t *testing.T) {
+	b := &backend{}
+	c := serveProxy(t, b)
+	if err := read(withRoles(context.Background(), "reader"), c); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.received(); got != nil {
+		t.Fatalf("expected no principal, got %v", got)
+	}
+}
+
+func TestProxyFields(t *testing.T) {
+	res := &testpb.Resource{Id: "id", Owner: "owner"}
+	tests := []struct {
+		name  string
+		files *protoregistry.Files
+		roles []string
+		owner string
+	}{
+		{
+			name:  "masked",
+			files: protoregistry.GlobalFiles,
+			roles: []string{"reader"},
+		},
+		{
+			name:  "allowed",
+			files: protoregistry.GlobalFiles,
+			roles: []string{"admin"},
+			owner: "owner",
+		},
+		{
+			name:  "without descriptors",
+			roles: []string{"reader"},
+			owner: "owner",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := serveProxy(t, &backend{}, WithProxySigningKey(testKey), WithProxyDescriptors(tt.files))
+			ctx := withRoles(context.Background(), tt.roles...)
+			got, err := c.Read(ctx, &testpb.Request{Resource: res})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.GetResource().GetOwner() != tt.owner {
+				t.Fatalf("expected owner %q, got %q", tt.owner, got.GetResource().GetOwner())
+			}
+			if got.GetResource().GetId() != "id" {
+				t.Fatalf("expected id to be forwarded, got %q", got.GetResource().GetId())
+			}
+			children, err := watch(ctx, c, &testpb.Request{Resource: &testpb.Resource{Children: []*testpb.Resource{res, res}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(children) != 2 {
+				t.Fatalf("expected 2 resources, got %d", len(children))
+			}
+			for _, v := range children {
+				if v.GetOwner() != tt.owner {
+					t.Fatalf("expected streamed owner %q, got %q", tt.owner, v.GetOwner())
+				}
+			}
+		})
+	}
+}
+
+func TestProxyCheckFields(t *testing.T) {
+	req := &testpb.Request{Resource: &testpb.Resource{Id: "id", Status: testpb.Resource_ACTIVE}}
+	tests := []struct {
+		name  string
+		files *protoregistry.Files
+		roles []string
+		code  codes.Code
+	}{
+		{
+			name:  "denied",
+			files: protoregistry.GlobalFiles,
+			roles: []string{"writer"},
+			code:  codes.PermissionDenied,
+		},
+		{
+			name:  "allowed",
+			files: protoregistry.GlobalFiles,
+			roles: []string{"admin"},
+		},
+		{
+			name:  "without descriptors",
+			roles: []string{"writer"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := serveProxy(t, &backend{}, WithProxySigningKey(testKey), WithProxyDescriptors(tt.files))
+			ctx := withRoles(context.Background(), tt.roles...)
+			_, err := c.Write(ctx, req)
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("expected %v, got %v: %v", tt.code, got, err)
+			}
+			s, err := c.Upload(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Send(&testpb.Request{Resource: &testpb.Resource{Id: "id"}}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Send(req); err != nil && tt.code == codes.OK {
+				t.Fatal(err)
+			}
+			res, err := s.CloseAndRecv()
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("expected streamed %v, got %v: %v", tt.code, got, err)
+			}
+			if err == nil && res.GetResource().GetStatus() != testpb.Resource_ACTIVE {
+				t.Fatalf("expected the last resource, got %v", res.GetResource())
+			}
+		})
+	}
+}
+
+func TestProxyHeaders(t *testing.T) {
+	c := serveProxy(t, &backend{}, WithProxySigningKey(testKey))
+	tests := []struct {
+		name string
+		ctx  context.Context
+		code codes.Code
+	}{
+		{
+			name: "no messages",
+			ctx:  withRoles(context.Background(), "reader"),
+		},
+		{
+			name: "backend failure",
+			ctx:  metadata.AppendToOutgoingContext(withRoles(context.Background(), "reader"), "fail", "true"),
+			code: codes.Internal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := c.Watch(tt.ctx, &testpb.Request{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.Recv(); status.Code(err) != tt.code && !(tt.code == codes.OK && err == io.EOF) {
+				t.Fatalf("expected %v, got %v", tt.code, err)
+			}
+			h, err := s.Header()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := h.Get("x-backend"); len(got) != 1 || got[0] != "value" {
+				t.Errorf("expected the backend header, got %v", h)
+			}
+		})
+	}
+}
+
+func read(ctx context.Context, c testpb.TestServiceClient) error {
+	_, err := c.Read(ctx, &testpb.Request{})
+	return err
+}
+
+func write(ctx context.Context, c testpb.TestServiceClient) error {
+	_, err := c.Write(ctx, &testpb.Request{})
+	return err
+}
+
+func public(ctx context.Context, c testpb.TestServiceClient) error {
+	_, err := c.Public(ctx, &testpb.Request{})
+	return err
+}
@@ -17,10 +17,16 @@ package grpc_rbac
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/mikespook/gorbac/v2"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
 var _ RBAC = (*rbac)(nil)
@@ -29,24 +35,87 @@ type RBAC interface {
 	RBACBackend
 	Interceptors
 	Register(desc *grpc.ServiceDesc)
+	// RegisterMethods registers the methods by their full names, e.g. /example.ResourceService/Create,
+	// which are also the connect procedures names, see ServiceMethods
+	RegisterMethods(fullMethods ...string) error
+	// RegisterDescriptor registers the service methods, roles and rules declared by its rbac options, without generated code
+	RegisterDescriptor(sd protoreflect.ServiceDescriptor) error
+	// RegisterFromRegistry registers the package roles and the services declaring rbac options of all the files
+	RegisterFromRegistry(files *protoregistry.Files) error
+	// RegisterPublic registers methods callable by anyone, with or without roles
+	RegisterPublic(fullMethods ...string)
+	// RegisterFields registers the message fields rules, merged with the rules already registered for the message
+	RegisterFields(message protoreflect.FullName, rules FieldRules)
+	// DescribeRoles registers the roles information
+	DescribeRoles(infos ...RoleInfo)
+	// RoleInfo returns the role information, only its ID is set if the role was not described
+	RoleInfo(id string) (RoleInfo, bool)
+	// RoleInfos returns the information of all the roles in the engine, sorted by id
+	RoleInfos() []RoleInfo
+	// RegisterStreamFilter registers the filter of the messages sent on the method server streams
+	RegisterStreamFilter(fullMethod string, fn StreamFilter)
+	// DroppedMessages returns the number of messages dropped by the method stream filter
+	DroppedMessages(fullMethod string) uint64
+	// NotifyPolicyChange triggers the streams re-authorization, it must be called when
+	// the roles returned by the RoleFunc may have changed
+	NotifyPolicyChange()
+	// HTTPMiddleware returns a middleware authorizing the HTTP requests as calls to the routes gRPC methods
+	HTTPMiddleware(routes ...HTTPRoute) func(http.Handler) http.Handler
+	// Proxy returns a handler forwarding the calls as raw frames to the backend once authorized, to be used
+	// with grpc.UnknownServiceHandler and ProxyCodec. The caller roles are passed to the backend in a signed principal.
+	Proxy(backend grpc.ClientConnInterface, opts ...ProxyOption) grpc.StreamHandler
+	// Authorize resolves the caller roles and authorizes the call of the method, for the transports without
+	// gRPC interceptors. It returns the handler context, holding the caller roles, see RolesFromContext.
+	Authorize(ctx context.Context, fullMethod string) (context.Context, error)
+	// CheckFields returns a PermissionDenied error if the message has fields the caller roles
+	// in the context are not allowed to set
+	CheckFields(ctx context.Context, msg interface{}) error
+	// MaskFields clears the message fields the caller roles in the context are not allowed to see
+	MaskFields(ctx context.Context, msg interface{}) interface{}
 }
 
 func New(opts ...Option) RBAC {
-	r := &rbac{rbac: gorbac.New()}
+	r := &rbac{rbac: gorbac.New(), changed: make(chan struct{}), errDetails: ErrorDetailsReason}
 	for _, v := range opts {
 		v(r)
 	}
 	if r.roleFunc == nil {
 		r.roleFunc = UnimplementedRoleFunc
 	}
+	if r.errMapper == nil {
+		r.errMapper = DefaultErrorMapper
+	}
+	r.policySize()
 	return r
 }
 
 type rbac struct {
-	rbac     *gorbac.RBAC
-	reg      sync.Map
-	roleFunc RoleFunc
-	assertFn gorbac.AssertionFunc
+	rbac      *gorbac.RBAC
+	reg       sync.Map
+	public    sync.Map
+	infos     sync.Map
+	fields    sync.Map
+	fieldsMu  sync.Mutex
+	masked    sync.Map
+	protected sync.Map
+	filters   sync.Map
+	roleFunc  RoleFunc
+	assertFn  gorbac.AssertionFunc
+
+	clientRoleFunc RoleFunc
+	clientCreds    []credentials.PerRPCCredentials
+
+	errDetails ErrorDetailsLevel
+	errMapper  ErrorMapper
+
+	metrics Metrics
+	tracer  Tracer
+
+	reauth         bool
+	reauthInterval time.Duration
+
+	mu      sync.Mutex
+	changed chan struct{}
 }
 
 func (r *rbac) Register(desc *grpc.ServiceDesc) {
@@ -58,6 +127,57 @@ func (r *rbac) Register(desc *grpc.ServiceDesc) {
 		f := fmt.Sprintf("/%s/%s", desc.ServiceName, v.StreamName)
 		r.reg.Store(f, GRPCPermission{fullMethod: f, serviceName: desc.ServiceName, methodOrStreamName: v.StreamName})
 	}
+	r.NotifyPolicyChange()
+}
+
+func (r *rbac) RegisterMethods(fullMethods ...string) error {
+	var errs []error
+	for _, v := range fullMethods {
+		parts := strings.Split(strings.TrimPrefix(v, "/"), "/")
+		if !strings.HasPrefix(v, "/") || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			errs = append(errs, fmt.Errorf("invalid method full name: '%s'", v))
+			continue
+		}
+		r.reg.Store(v, GRPCPermission{fullMethod: v, serviceName: parts[0], methodOrStreamName: parts[1]})
+	}
+	r.NotifyPolicyChange()
+	return JoinErrors(errs...)
+}
+
+// ServiceMethods returns the full names of the service methods, e.g. to be registered with RBAC.RegisterMethods
+func ServiceMethods(sd protoreflect.ServiceDescriptor) []string {
+	var out []string
+	for i := 0; i < sd.Methods().Len(); i++ {
+		out = append(out, fmt.Sprintf("/%s/%s", sd.FullName(), sd.Methods().Get(i).Name()))
+	}
+	return out
+}
+
+func (r *rbac) RegisterPublic(fullMethods ...string) {
+	for _, v := range fullMethods {
+		r.public.Store(v, struct{}{})
+	}
+	r.NotifyPolicyChange()
+}
+
+func (r *rbac) isPublic(fullMethod string) bool {
+	_, ok := r.public.Load(fullMethod)
+	return ok
+}
+
+func (r *rbac) NotifyPolicyChange() {
+	r.mu.Lock()
+	close(r.changed)
+	r.changed = make(chan struct{})
+	r.mu.Unlock()
+	r.policySize()
+}
+
+// changes returns a channel closed on the next policy change
+func (r *rbac) changes() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.changed
 }
 
 type key struct{}
@@ -41,6 +41,8 @@ type RBAC struct {
 	unknownFields protoimpl.UnknownFields
 
 	Roles []string `protobuf:"bytes,1,rep,name=roles" json:"roles,omitempty"`
+	// public methods can be called by anyone, with or without roles
+	Public *bool `protobuf:"varint,2,opt,name=public" json:"public,omitempty"`
 }
 
 func (x *RBAC) Reset() {
@@ -82,6 +84,13 @@ func (x *RBAC) GetRoles() []string {
 	return nil
 }
 
+func (x *RBAC) GetPublic() bool {
+	if x != nil && x.Public != nil {
+		return *x.Public
+	}
+	return false
+}
+
 type RoleDefinition struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -129,6 +138,63 @@ func (x *RoleDefinition) GetRoles() []*Role {
 	return nil
 }
 
+type Field struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// roles are the roles allowed to see the field in responses
+	Roles []string `protobuf:"bytes,1,rep,name=roles" json:"roles,omitempty"`
+	// write are the roles allowed to set the field in requests
+	Write []string `protobuf:"bytes,2,rep,name=write" json:"write,omitempty"`
+}
+
+func (x *Field) Reset() {
+	*x = Field{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rbac_rbac_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Field) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Field) ProtoMessage() {}
+
+func (x *Field) ProtoReflect() protoreflect.Message {
+	mi := &file_rbac_rbac_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Field.ProtoReflect.Descriptor instead.
+func (*Field) Descriptor() ([]byte, []int) {
+	return file_rbac_rbac_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Field) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *Field) GetWrite() []string {
+	if x != nil {
+		return x.Write
+	}
+	return nil
+}
+
 type Role struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -136,12 +202,20 @@ type Role struct {
 
 	Name    *string  `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 	Parents []string `protobuf:"bytes,2,rep,name=parents" json:"parents,omitempty"`
+	// description is the role description, used in the generated code and documentation
+	Description *string `protobuf:"bytes,3,opt,name=description" json:"description,omitempty"`
+	// display_name is the role human readable name
+	DisplayName *string `protobuf:"bytes,4,opt,name=display_name,json=displayName" json:"display_name,omitempty"`
+	// deprecated roles are still registered, but marked as deprecated in the generated code
+	Deprecated *bool `protobuf:"varint,5,opt,name=deprecated" json:"deprecated,omitempty"`
+	// labels are arbitrary role metadata
+	Labels map[string]string `protobuf:"bytes,6,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (x *Role) Reset() {
 	*x = Role{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_rbac_rbac_proto_msgTypes[2]
+		mi := &file_rbac_rbac_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -154,7 +228,7 @@ func (x *Role) String() string {
 func (*Role) ProtoMessage() {}
 
 func (x *Role) ProtoReflect() protoreflect.Message {
-	mi := &file_rbac_rbac_proto_msgTypes[2]
+	mi := &file_rbac_rbac_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -167,7 +241,7 @@ func (x *Role) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Role.ProtoReflect.Descriptor instead.
 func (*Role) Descriptor() ([]byte, []int) {
-	return file_rbac_rbac_proto_rawDescGZIP(), []int{2}
+	return file_rbac_rbac_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Role) GetName() string {
@@ -184,7 +258,476 @@ func (x *Role) GetParents() []string {
 	return nil
 }
 
+func (x *Role) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *Role) GetDisplayName() string {
+	if x != nil && x.DisplayName != nil {
+		return *x.DisplayName
+	}
+	return ""
+}
+
+func (x *Role) GetDeprecated() bool {
+	if x != nil && x.Deprecated != nil {
+		return *x.Deprecated
+	}
+	return false
+}
+
+func (x *Role) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+// Manifest is the rbac policy declared by a proto file options, generated by protoc-gen-go-rbac
+// with the manifest parameter
+type Manifest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// file is the proto file name
+	File *string `protobuf:"bytes,1,opt,name=file" json:"file,omitempty"`
+	// package is the proto package name
+	Package *string `protobuf:"bytes,2,opt,name=package" json:"package,omitempty"`
+	// roles are the package shared roles defined in the file
+	Roles    []*ManifestRole    `protobuf:"bytes,3,rep,name=roles" json:"roles,omitempty"`
+	Services []*ManifestService `protobuf:"bytes,4,rep,name=services" json:"services,omitempty"`
+}
+
+func (x *Manifest) Reset() {
+	*x = Manifest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rbac_rbac_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Manifest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Manifest) ProtoMessage() {}
+
+func (x *Manifest) ProtoReflect() protoreflect.Message {
+	mi := &file_rbac_rbac_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Manifest.ProtoReflect.Descriptor instead.
+func (*Manifest) Descriptor() ([]byte, []int) {
+	return file_rbac_rbac_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Manifest) GetFile() string {
+	if x != nil && x.File != nil {
+		return *x.File
+	}
+	return ""
+}
+
+func (x *Manifest) GetPackage() string {
+	if x != nil && x.Package != nil {
+		return *x.Package
+	}
+	return ""
+}
+
+func (x *Manifest) GetRoles() []*ManifestRole {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *Manifest) GetServices() []*ManifestService {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+type ManifestService struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// name is the service full name, e.g. example.ResourceService
+	Name    *string           `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Methods []*ManifestMethod `protobuf:"bytes,2,rep,name=methods" json:"methods,omitempty"`
+	// roles are the roles the service defines or grants permissions to
+	Roles []*ManifestRole `protobuf:"bytes,3,rep,name=roles" json:"roles,omitempty"`
+	// messages are the fields access rules of the service methods messages
+	Messages []*ManifestMessage `protobuf:"bytes,4,rep,name=messages" json:"messages,omitempty"`
+}
+
+func (x *ManifestService) Reset() {
+	*x = ManifestService{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rbac_rbac_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ManifestService) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ManifestService) ProtoMessage() {}
+
+func (x *ManifestService) ProtoReflect() protoreflect.Message {
+	mi := &file_rbac_rbac_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ManifestService.ProtoReflect.Descriptor instead.
+func (*ManifestService) Descriptor() ([]byte, []int) {
+	return file_rbac_rbac_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ManifestService) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *ManifestService) GetMethods() []*ManifestMethod {
+	if x != nil {
+		return x.Methods
+	}
+	return nil
+}
+
+func (x *ManifestService) GetRoles() []*ManifestRole {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *ManifestService) GetMessages() []*ManifestMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type ManifestMethod struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name *string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// full_method is the gRPC full method name, e.g. /example.ResourceService/Create
+	FullMethod *string `protobuf:"bytes,2,opt,name=full_method,json=fullMethod" json:"full_method,omitempty"`
+	// roles are the ids of the roles allowed to call the method
+	Roles []string `protobuf:"bytes,3,rep,name=roles" json:"roles,omitempty"`
+	// public methods can be called by anyone, with or without roles
+	Public *bool `protobuf:"varint,4,opt,name=public" json:"public,omitempty"`
+}
+
+func (x *ManifestMethod) Reset() {
+	*x = ManifestMethod{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rbac_rbac_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ManifestMethod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ManifestMethod) ProtoMessage() {}
+
+func (x *ManifestMethod) ProtoReflect() protoreflect.Message {
+	mi := &file_rbac_rbac_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ManifestMethod.ProtoReflect.Descriptor instead.
+func (*ManifestMethod) Descriptor() ([]byte, []int) {
+	return file_rbac_rbac_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ManifestMethod) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *ManifestMethod) GetFullMethod() string {
+	if x != nil && x.FullMethod != nil {
+		return *x.FullMethod
+	}
+	return ""
+}
+
+func (x *ManifestMethod) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *ManifestMethod) GetPublic() bool {
+	if x != nil && x.Public != nil {
+		return *x.Public
+	}
+	return false
+}
+
+type ManifestRole struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id *string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	// parents are the role parents ids
+	Parents     []string          `protobuf:"bytes,2,rep,name=parents" json:"parents,omitempty"`
+	Description *string           `protobuf:"bytes,3,opt,name=description" json:"description,omitempty"`
+	DisplayName *string           `protobuf:"bytes,4,opt,name=display_name,json=displayName" json:"display_name,omitempty"`
+	Deprecated  *bool             `protobuf:"varint,5,opt,name=deprecated" json:"deprecated,omitempty"`
+	Labels      map[string]string `protobuf:"bytes,6,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (x *ManifestRole) Reset() {
+	*x = ManifestRole{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rbac_rbac_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ManifestRole) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ManifestRole) ProtoMessage() {}
+
+func (x *ManifestRole) ProtoReflect() protoreflect.Message {
+	mi := &file_rbac_rbac_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ManifestRole.ProtoReflect.Descriptor instead.
+func (*ManifestRole) Descriptor() ([]byte, []int) {
+	return file_rbac_rbac_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ManifestRole) GetId() string {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return ""
+}
+
+func (x *ManifestRole) GetParents() []string {
+	if x != nil {
+		return x.Parents
+	}
+	return nil
+}
+
+func (x *ManifestRole) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *ManifestRole) GetDisplayName() string {
+	if x != nil && x.DisplayName != nil {
+		return *x.DisplayName
+	}
+	return ""
+}
+
+func (x *ManifestRole) GetDeprecated() bool {
+	if x != nil && x.Deprecated != nil {
+		return *x.Deprecated
+	}
+	return false
+}
+
+func (x *ManifestRole) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type ManifestMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// name is the message full name
+	Name   *string          `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Fields []*ManifestField `protobuf:"bytes,2,rep,name=fields" json:"fields,omitempty"`
+}
+
+func (x *ManifestMessage) Reset() {
+	*x = ManifestMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rbac_rbac_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ManifestMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ManifestMessage) ProtoMessage() {}
+
+func (x *ManifestMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_rbac_rbac_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ManifestMessage.ProtoReflect.Descriptor instead.
+func (*ManifestMessage) Descriptor() ([]byte, []int) {
+	return file_rbac_rbac_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ManifestMessage) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *ManifestMessage) GetFields() []*ManifestField {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type ManifestField struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name *string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// read are the ids of the roles allowed to see the field in responses
+	Read []string `protobuf:"bytes,2,rep,name=read" json:"read,omitempty"`
+	// write are the ids of the roles allowed to set the field in requests
+	Write []string `protobuf:"bytes,3,rep,name=write" json:"write,omitempty"`
+}
+
+func (x *ManifestField) Reset() {
+	*x = ManifestField{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rbac_rbac_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ManifestField) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ManifestField) ProtoMessage() {}
+
+func (x *ManifestField) ProtoReflect() protoreflect.Message {
+	mi := &file_rbac_rbac_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ManifestField.ProtoReflect.Descriptor instead.
+func (*ManifestField) Descriptor() ([]byte, []int) {
+	return file_rbac_rbac_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ManifestField) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *ManifestField) GetRead() []string {
+	if x != nil {
+		return x.Read
+	}
+	return nil
+}
+
+func (x *ManifestField) GetWrite() []string {
+	if x != nil {
+		return x.Write
+	}
+	return nil
+}
+
 var file_rbac_rbac_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.FileOptions)(nil),
+		ExtensionType: (*RoleDefinition)(nil),
+		Field:         1193,
+		Name:          "rbac.file_def",
+		Tag:           "bytes,1193,opt,name=file_def",
+		Filename:      "rbac/rbac.proto",
+	},
 	{
 		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
 		ExtensionType: (*RoleDefinition)(nil),
@@ -201,18 +744,40 @@ var file_rbac_rbac_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "bytes,1191,opt,name=access",
 		Filename:      "rbac/rbac.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*Field)(nil),
+		Field:         1192,
+		Name:          "rbac.field",
+		Tag:           "bytes,1192,opt,name=field",
+		Filename:      "rbac/rbac.proto",
+	},
 }
 
+// Extension fields to descriptorpb.FileOptions.
+var (
+	// file_def defines the package shared roles
+	//
+	// optional rbac.RoleDefinition file_def = 1193;
+	E_FileDef = &file_rbac_rbac_proto_extTypes[0]
+)
+
 // Extension fields to descriptorpb.ServiceOptions.
 var (
 	// optional rbac.RoleDefinition def = 1190;
-	E_Def = &file_rbac_rbac_proto_extTypes[0]
+	E_Def = &file_rbac_rbac_proto_extTypes[1]
 )
 
 // Extension fields to descriptorpb.MethodOptions.
 var (
 	// optional rbac.RBAC access = 1191;
-	E_Access = &file_rbac_rbac_proto_extTypes[1]
+	E_Access = &file_rbac_rbac_proto_extTypes[2]
+)
+
+// Extension fields to descriptorpb.FieldOptions.
+var (
+	// optional rbac.Field field = 1192;
+	E_Field = &file_rbac_rbac_proto_extTypes[3]
 )
 
 var File_rbac_rbac_proto protoreflect.FileDescriptor
@@ -221,27 +786,109 @@ var file_rbac_rbac_proto_rawDesc = []byte{
 	0x0a, 0x0f, 0x72, 0x62, 0x61, 0x63, 0x2f, 0x72, 0x62, 0x61, 0x63, 0x2e, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x12, 0x04, 0x72, 0x62, 0x61, 0x63, 0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70,
-	0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x1c, 0x0a, 0x04, 0x52, 0x42, 0x41,
+	0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x34, 0x0a, 0x04, 0x52, 0x42, 0x41,
 	0x43, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
-	0x52, 0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73, 0x22, 0x32, 0x0a, 0x0e, 0x52, 0x6f, 0x6c, 0x65, 0x44,
-	0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x05, 0x72, 0x6f, 0x6c,
-	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x72, 0x62, 0x61, 0x63, 0x2e,
-	0x52, 0x6f, 0x6c, 0x65, 0x52, 0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73, 0x22, 0x34, 0x0a, 0x04, 0x52,
-	0x6f, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x72, 0x65, 0x6e,
-	0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74,
-	0x73, 0x3a, 0x48, 0x0a, 0x03, 0x64, 0x65, 0x66, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
-	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69,
-	0x63, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xa6, 0x09, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x14, 0x2e, 0x72, 0x62, 0x61, 0x63, 0x2e, 0x52, 0x6f, 0x6c, 0x65, 0x44, 0x65, 0x66, 0x69,
-	0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x03, 0x64, 0x65, 0x66, 0x3a, 0x43, 0x0a, 0x06, 0x61,
-	0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x1e, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x4f, 0x70,
-	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xa7, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x72,
-	0x62, 0x61, 0x63, 0x2e, 0x52, 0x42, 0x41, 0x43, 0x52, 0x06, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73,
-	0x42, 0x24, 0x5a, 0x22, 0x67, 0x6f, 0x2e, 0x6c, 0x69, 0x6e, 0x6b, 0x61, 0x2e, 0x63, 0x6c, 0x6f,
-	0x75, 0x64, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2d, 0x72, 0x62, 0x61, 0x63, 0x2f, 0x72, 0x62, 0x61,
-	0x63, 0x3b, 0x72, 0x62, 0x61, 0x63,
+	0x52, 0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x75, 0x62, 0x6c, 0x69,
+	0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x22,
+	0x32, 0x0a, 0x0e, 0x52, 0x6f, 0x6c, 0x65, 0x44, 0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x20, 0x0a, 0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0a, 0x2e, 0x72, 0x62, 0x61, 0x63, 0x2e, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x05, 0x72, 0x6f,
+	0x6c, 0x65, 0x73, 0x22, 0x33, 0x0a, 0x05, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x72, 0x6f, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x72, 0x6f, 0x6c,
+	0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x72, 0x69, 0x74, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x05, 0x77, 0x72, 0x69, 0x74, 0x65, 0x22, 0x84, 0x02, 0x0a, 0x04, 0x52, 0x6f, 0x6c,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x73, 0x12,
+	0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79,
+	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x65, 0x70, 0x72, 0x65, 0x63, 0x61, 0x74,
+	0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x64, 0x65, 0x70, 0x72, 0x65, 0x63,
+	0x61, 0x74, 0x65, 0x64, 0x12, 0x2e, 0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x06,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x72, 0x62, 0x61, 0x63, 0x2e, 0x52, 0x6f, 0x6c, 0x65,
+	0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x6c, 0x61,
+	0x62, 0x65, 0x6c, 0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
+	0x95, 0x01, 0x0a, 0x08, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x12, 0x28, 0x0a, 0x05, 0x72, 0x6f,
+	0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x72, 0x62, 0x61, 0x63,
+	0x2e, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x05, 0x72,
+	0x6f, 0x6c, 0x65, 0x73, 0x12, 0x31, 0x0a, 0x08, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73,
+	0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x62, 0x61, 0x63, 0x2e, 0x4d, 0x61,
+	0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x52, 0x08, 0x73,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x22, 0xb2, 0x01, 0x0a, 0x0f, 0x4d, 0x61, 0x6e, 0x69,
+	0x66, 0x65, 0x73, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x2e, 0x0a, 0x07, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x14, 0x2e, 0x72, 0x62, 0x61, 0x63, 0x2e, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74,
+	0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x52, 0x07, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x73, 0x12,
+	0x28, 0x0a, 0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12,
+	0x2e, 0x72, 0x62, 0x61, 0x63, 0x2e, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x52, 0x6f,
+	0x6c, 0x65, 0x52, 0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73, 0x12, 0x31, 0x0a, 0x08, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x62,
+	0x61, 0x63, 0x2e, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x52, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x22, 0x73, 0x0a, 0x0e,
+	0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x66, 0x75, 0x6c, 0x6c, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x66, 0x75, 0x6c, 0x6c, 0x4d, 0x65, 0x74,
+	0x68, 0x6f, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x75, 0x62,
+	0x6c, 0x69, 0x63, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x70, 0x75, 0x62, 0x6c, 0x69,
+	0x63, 0x22, 0x90, 0x02, 0x0a, 0x0c, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x52, 0x6f,
+	0x6c, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x20, 0x0a, 0x0b,
+	0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21,
+	0x0a, 0x0c, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x4e, 0x61, 0x6d,
+	0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x65, 0x70, 0x72, 0x65, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x64, 0x65, 0x70, 0x72, 0x65, 0x63, 0x61, 0x74, 0x65,
+	0x64, 0x12, 0x36, 0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x1e, 0x2e, 0x72, 0x62, 0x61, 0x63, 0x2e, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73,
+	0x74, 0x52, 0x6f, 0x6c, 0x65, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x4c, 0x61, 0x62,
+	0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0x52, 0x0a, 0x0f, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x2b, 0x0a, 0x06, 0x66,
+	0x69, 0x65, 0x6c, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x72, 0x62,
+	0x61, 0x63, 0x2e, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x46, 0x69, 0x65, 0x6c, 0x64,
+	0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x22, 0x4d, 0x0a, 0x0d, 0x4d, 0x61, 0x6e, 0x69,
+	0x66, 0x65, 0x73, 0x74, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x72, 0x65, 0x61, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x72, 0x65, 0x61,
+	0x64, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x72, 0x69, 0x74, 0x65, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x05, 0x77, 0x72, 0x69, 0x74, 0x65, 0x3a, 0x4e, 0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x5f,
+	0x64, 0x65, 0x66, 0x12, 0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0xa9, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x72, 0x62, 0x61, 0x63, 0x2e,
+	0x52, 0x6f, 0x6c, 0x65, 0x44, 0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07,
+	0x66, 0x69, 0x6c, 0x65, 0x44, 0x65, 0x66, 0x3a, 0x48, 0x0a, 0x03, 0x64, 0x65, 0x66, 0x12, 0x1f,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0xa6, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x72, 0x62, 0x61, 0x63, 0x2e, 0x52, 0x6f,
+	0x6c, 0x65, 0x44, 0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x03, 0x64, 0x65,
+	0x66, 0x3a, 0x43, 0x0a, 0x06, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x1e, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65,
+	0x74, 0x68, 0x6f, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xa7, 0x09, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x72, 0x62, 0x61, 0x63, 0x2e, 0x52, 0x42, 0x41, 0x43, 0x52, 0x06,
+	0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x3a, 0x41, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12,
+	0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xa8,
+	0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x72, 0x62, 0x61, 0x63, 0x2e, 0x46, 0x69, 0x65,
+	0x6c, 0x64, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x42, 0x24, 0x5a, 0x22, 0x67, 0x6f, 0x2e,
+	0x6c, 0x69, 0x6e, 0x6b, 0x61, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x2f, 0x67, 0x72, 0x70, 0x63,
+	0x2d, 0x72, 0x62, 0x61, 0x63, 0x2f, 0x72, 0x62, 0x61, 0x63, 0x3b, 0x72, 0x62, 0x61, 0x63,
 }
 
 var (
@@ -256,25 +903,48 @@ func file_rbac_rbac_proto_rawDescGZIP() []byte {
 	return file_rbac_rbac_proto_rawDescData
 }
 
-var file_rbac_rbac_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_rbac_rbac_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_rbac_rbac_proto_goTypes = []any{
 	(*RBAC)(nil),                        // 0: rbac.RBAC
 	(*RoleDefinition)(nil),              // 1: rbac.RoleDefinition
-	(*Role)(nil),                        // 2: rbac.Role
-	(*descriptorpb.ServiceOptions)(nil), // 3: google.protobuf.ServiceOptions
-	(*descriptorpb.MethodOptions)(nil),  // 4: google.protobuf.MethodOptions
+	(*Field)(nil),                       // 2: rbac.Field
+	(*Role)(nil),                        // 3: rbac.Role
+	(*Manifest)(nil),                    // 4: rbac.Manifest
+	(*ManifestService)(nil),             // 5: rbac.ManifestService
+	(*ManifestMethod)(nil),              // 6: rbac.ManifestMethod
+	(*ManifestRole)(nil),                // 7: rbac.ManifestRole
+	(*ManifestMessage)(nil),             // 8: rbac.ManifestMessage
+	(*ManifestField)(nil),               // 9: rbac.ManifestField
+	nil,                                 // 10: rbac.Role.LabelsEntry
+	nil,                                 // 11: rbac.ManifestRole.LabelsEntry
+	(*descriptorpb.FileOptions)(nil),    // 12: google.protobuf.FileOptions
+	(*descriptorpb.ServiceOptions)(nil), // 13: google.protobuf.ServiceOptions
+	(*descriptorpb.MethodOptions)(nil),  // 14: google.protobuf.MethodOptions
+	(*descriptorpb.FieldOptions)(nil),   // 15: google.protobuf.FieldOptions
 }
 var file_rbac_rbac_proto_depIdxs = []int32{
-	2, // 0: rbac.RoleDefinition.roles:type_name -> rbac.Role
-	3, // 1: rbac.def:extendee -> google.protobuf.ServiceOptions
-	4, // 2: rbac.access:extendee -> google.protobuf.MethodOptions
-	1, // 3: rbac.def:type_name -> rbac.RoleDefinition
-	0, // 4: rbac.access:type_name -> rbac.RBAC
-	5, // [5:5] is the sub-list for method output_type
-	5, // [5:5] is the sub-list for method input_type
-	3, // [3:5] is the sub-list for extension type_name
-	1, // [1:3] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	3,  // 0: rbac.RoleDefinition.roles:type_name -> rbac.Role
+	10, // 1: rbac.Role.labels:type_name -> rbac.Role.LabelsEntry
+	7,  // 2: rbac.Manifest.roles:type_name -> rbac.ManifestRole
+	5,  // 3: rbac.Manifest.services:type_name -> rbac.ManifestService
+	6,  // 4: rbac.ManifestService.methods:type_name -> rbac.ManifestMethod
+	7,  // 5: rbac.ManifestService.roles:type_name -> rbac.ManifestRole
+	8,  // 6: rbac.ManifestService.messages:type_name -> rbac.ManifestMessage
+	11, // 7: rbac.ManifestRole.labels:type_name -> rbac.ManifestRole.LabelsEntry
+	9,  // 8: rbac.ManifestMessage.fields:type_name -> rbac.ManifestField
+	12, // 9: rbac.file_def:extendee -> google.protobuf.FileOptions
+	13, // 10: rbac.def:extendee -> google.protobuf.ServiceOptions
+	14, // 11: rbac.access:extendee -> google.protobuf.MethodOptions
+	15, // 12: rbac.field:extendee -> google.protobuf.FieldOptions
+	1,  // 13: rbac.file_def:type_name -> rbac.RoleDefinition
+	1,  // 14: rbac.def:type_name -> rbac.RoleDefinition
+	0,  // 15: rbac.access:type_name -> rbac.RBAC
+	2,  // 16: rbac.field:type_name -> rbac.Field
+	17, // [17:17] is the sub-list for method output_type
+	17, // [17:17] is the sub-list for method input_type
+	13, // [13:17] is the sub-list for extension type_name
+	9,  // [9:13] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_rbac_rbac_proto_init() }
@@ -308,6 +978,18 @@ func file_rbac_rbac_proto_init() {
 			}
 		}
 		file_rbac_rbac_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*Field); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rbac_rbac_proto_msgTypes[3].Exporter = func(v any, i int) any {
 			switch v := v.(*Role); i {
 			case 0:
 				return &v.state
@@ -319,6 +1001,78 @@ func file_rbac_rbac_proto_init() {
 				return nil
 			}
 		}
+		file_rbac_rbac_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*Manifest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rbac_rbac_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*ManifestService); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rbac_rbac_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*ManifestMethod); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rbac_rbac_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*ManifestRole); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rbac_rbac_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*ManifestMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rbac_rbac_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*ManifestField); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -326,8 +1080,8 @@ func file_rbac_rbac_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_rbac_rbac_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   3,
-			NumExtensions: 2,
+			NumMessages:   12,
+			NumExtensions: 4,
 			NumServices:   0,
 		},
 		GoTypes:           file_rbac_rbac_proto_goTypes,
@@ -0,0 +1,187 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+)
+
+const (
+	readMethod   = "/test.TestService/Read"
+	writeMethod  = "/test.TestService/Write"
+	publicMethod = "/test.TestService/Public"
+	watchMethod  = "/test.TestService/Watch"
+	uploadMethod = "/test.TestService/Upload"
+)
+
+// testServer echoes the requests resources
+type testServer struct {
+	testpb.UnimplementedTestServiceServer
+}
+
+func (testServer) Read(_ context.Context, req *testpb.Request) (*testpb.Response, error) {
+	return &testpb.Response{Resource: req.GetResource()}, nil
+}
+
+func (testServer) Write(_ context.Context, req *testpb.Request) (*testpb.Response, error) {
+	return &testpb.Response{Resource: req.GetResource()}, nil
+}
+
+func (testServer) Public(_ context.Context, req *testpb.Request) (*testpb.Response, error) {
+	return &testpb.Response{Resource: req.GetResource()}, nil
+}
+
+// Watch sends the request resource children, then waits for the stream to be done
+// if the resource id is "hold"
+func (testServer) Watch(req *testpb.Request, ss testpb.TestService_WatchServer) error {
+	for _, v := range req.GetResource().GetChildren() {
+		if err := ss.Send(&testpb.Response{Resource: v}); err != nil {
+			return err
+		}
+	}
+	if req.GetResource().GetId() == "hold" {
+		<-ss.Context().Done()
+		return ss.Context().Err()
+	}
+	return nil
+}
+
+// Upload returns the last received resource
+func (testServer) Upload(ss testpb.TestService_UploadServer) error {
+	res := &testpb.Response{}
+	for {
+		req, err := ss.Recv()
+		if err == io.EOF {
+			return ss.SendAndClose(res)
+		}
+		if err != nil {
+			return err
+		}
+		res.Resource = req.GetResource()
+	}
+}
+
+// incomingRoles resolves the roles from the incoming "role" metadata
+func incomingRoles(ctx context.Context) ([]Role, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var roles []Role
+	for _, v := range md.Get("role") {
+		roles = append(roles, NewStdRole(v))
+	}
+	return roles, nil
+}
+
+// withRoles returns the outgoing context sending the roles in the "role" metadata
+func withRoles(ctx context.Context, roles ...string) context.Context {
+	for _, v := range roles {
+		ctx = metadata.AppendToOutgoingContext(ctx, "role", v)
+	}
+	return ctx
+}
+
+// newTestRBAC returns an engine resolving the roles with incomingRoles, where the TestService policy is registered:
+// reader can call Read and Watch, writer can call Write and Upload, admin inherits both,
+// the Resource owner can only be read by admin and its status only written by admin.
+func newTestRBAC(t *testing.T, opts ...Option) RBAC {
+	t.Helper()
+	r := New(append([]Option{WithRoleFunc(incomingRoles)}, opts...)...)
+	perm := func(name string) Permission {
+		return NewGRPCPermission("test.TestService", name)
+	}
+	for _, err := range []error{
+		Grant(r, "reader", perm("Read"), perm("Watch")),
+		Grant(r, "writer", perm("Write"), perm("Upload")),
+		Inherit(r, "admin", "writer", "reader"),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.Register(&testpb.TestService_ServiceDesc)
+	r.RegisterPublic(publicMethod)
+	r.RegisterFields("test.Resource", FieldRules{
+		"owner":  {Read: []string{"admin"}},
+		"status": {Write: []string{"admin"}},
+	})
+	return r
+}
+
+// serve serves the testServer with the engine interceptors and returns a client connected to it
+func serve(t *testing.T, r RBAC, opts ...grpc.DialOption) testpb.TestServiceClient {
+	t.Helper()
+	return testpb.NewTestServiceClient(dialServer(t, grpc.NewServer(
+		grpc.UnaryInterceptor(r.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(r.StreamServerInterceptor()),
+	), opts...))
+}
+
+// dialServer registers the testServer to the server, serves it in memory and returns a connection to it
+func dialServer(t *testing.T, s *grpc.Server, opts ...grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+	if s.GetServiceInfo()["test.TestService"].Methods == nil {
+		testpb.RegisterTestServiceServer(s, testServer{})
+	}
+	return listen(t, s, opts...)
+}
+
+// listen serves the server in memory and returns a connection to it
+func listen(t *testing.T, s *grpc.Server, opts ...grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+	l := bufconn.Listen(1 << 20)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	cc, err := grpc.Dial("bufnet", append([]grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cc.Close()
+	})
+	return cc
+}
+
+// watch calls Watch and returns the received resources and the stream error, nil on io.EOF
+func watch(ctx context.Context, c testpb.TestServiceClient, req *testpb.Request) ([]*testpb.Resource, error) {
+	s, err := c.Watch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var out []*testpb.Resource
+	for {
+		res, err := s.Recv()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, res.GetResource())
+	}
+}
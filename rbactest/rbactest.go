@@ -0,0 +1,165 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rbactest runs the role × method access matrices generated by protoc-gen-go-rbac
+// with the tests parameter against a real engine and interceptors, through an in-process gRPC channel.
+package rbactest
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	grpc_rbac "go.linka.cloud/grpc-rbac"
+)
+
+// rolesKey is the metadata key used to pass the caller roles ids to the server
+const rolesKey = "rbactest-roles"
+
+// Case is an access matrix entry
+type Case struct {
+	// Role is the caller role id, empty for a caller without any role
+	Role string
+	// Method is the service method name
+	Method string
+	// Allowed is the expected access
+	Allowed bool
+}
+
+type options struct {
+	policies []func(rbac grpc_rbac.RBAC) error
+	opts     []grpc_rbac.Option
+}
+
+type Option func(o *options)
+
+// WithPolicy applies a custom policy to the engine after the generated registration,
+// e.g. to check that the custom policy changes keep the declared matrix
+func WithPolicy(fn func(rbac grpc_rbac.RBAC) error) Option {
+	return func(o *options) {
+		o.policies = append(o.policies, fn)
+	}
+}
+
+// WithEngineOptions adds engine options, the role function is always the rbactest one
+func WithEngineOptions(opts ...grpc_rbac.Option) Option {
+	return func(o *options) {
+		o.opts = append(o.opts, opts...)
+	}
+}
+
+// Run registers the service implementation on an in-process server using the engine interceptors,
+// then calls every matrix method as the case role and checks that the call is allowed or denied.
+// An allowed call may fail with any other error than PermissionDenied and Unauthenticated,
+// e.g. Unimplemented when impl is the generated Unimplemented server.
+func Run(t *testing.T, desc *grpc.ServiceDesc, impl interface{}, register func(rbac grpc_rbac.RBAC) error, cases []Case, opts ...Option) {
+	t.Helper()
+	var o options
+	for _, v := range opts {
+		v(&o)
+	}
+	rbac := grpc_rbac.New(append(o.opts, grpc_rbac.WithRoleFunc(roleFunc))...)
+	if err := register(rbac); err != nil {
+		t.Fatalf("register %s permissions: %v", desc.ServiceName, err)
+	}
+	for _, fn := range o.policies {
+		if err := fn(rbac); err != nil {
+			t.Fatalf("apply policy: %v", err)
+		}
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(rbac.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(rbac.StreamServerInterceptor()),
+	)
+	s.RegisterService(desc, impl)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, "bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	streams := make(map[string]grpc.StreamDesc)
+	for _, v := range desc.Streams {
+		streams[v.StreamName] = v
+	}
+	for _, c := range cases {
+		c := c
+		role := c.Role
+		if role == "" {
+			role = "<none>"
+		}
+		t.Run(role+"/"+c.Method, func(t *testing.T) {
+			ctx := ctx
+			if c.Role != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, rolesKey, c.Role)
+			}
+			fullMethod := "/" + desc.ServiceName + "/" + c.Method
+			var err error
+			if sd, ok := streams[c.Method]; ok {
+				err = callStream(ctx, conn, &sd, fullMethod)
+			} else {
+				err = conn.Invoke(ctx, fullMethod, &emptypb.Empty{}, &emptypb.Empty{})
+			}
+			switch code := status.Code(err); {
+			case c.Allowed && (code == codes.PermissionDenied || code == codes.Unauthenticated):
+				t.Errorf("%s should be allowed to call %s: %v", role, c.Method, err)
+			case !c.Allowed && code != codes.PermissionDenied && code != codes.Unauthenticated:
+				t.Errorf("%s should not be allowed to call %s: %v", role, c.Method, err)
+			}
+		})
+	}
+}
+
+func callStream(ctx context.Context, conn *grpc.ClientConn, desc *grpc.StreamDesc, fullMethod string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ss, err := conn.NewStream(ctx, desc, fullMethod)
+	if err != nil {
+		return err
+	}
+	// the send error, if any, is returned by RecvMsg
+	_ = ss.SendMsg(&emptypb.Empty{})
+	_ = ss.CloseSend()
+	return ss.RecvMsg(&emptypb.Empty{})
+}
+
+// roleFunc returns the roles passed by Run in the incoming metadata
+func roleFunc(ctx context.Context) ([]grpc_rbac.Role, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var roles []grpc_rbac.Role
+	for _, v := range md.Get(rolesKey) {
+		roles = append(roles, grpc_rbac.NewStdRole(v))
+	}
+	return roles, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mikespook/gorbac/v2"
+)
+
+// Grant adds the role to the engine if it does not exist yet, and assigns it the permissions.
+func Grant(r RBACBackend, id string, perms ...Permission) error {
+	role, _, err := r.Get(id)
+	if errors.Is(err, gorbac.ErrRoleNotExist) {
+		role = NewStdRole(id)
+		err = r.Add(role)
+	}
+	if err != nil {
+		return fmt.Errorf("add %s: %w", id, err)
+	}
+	if len(perms) == 0 {
+		return nil
+	}
+	a, ok := role.(interface{ Assign(p Permission) error })
+	if !ok {
+		return fmt.Errorf("%s: %T does not support permissions assignment", id, role)
+	}
+	for _, v := range perms {
+		if err := a.Assign(v); err != nil {
+			return fmt.Errorf("assign %s to %s: %w", v.ID(), id, err)
+		}
+	}
+	return nil
+}
+
+// Inherit sets the role parents, adding the role and its parents to the engine if they do not exist yet.
+func Inherit(r RBACBackend, id string, parents ...string) error {
+	if err := Grant(r, id); err != nil {
+		return err
+	}
+	for _, v := range parents {
+		if err := Grant(r, v); err != nil {
+			return err
+		}
+		if err := r.SetParent(id, v); err != nil {
+			return fmt.Errorf("set %s parent %s: %w", id, v, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGrant(t *testing.T) {
+	read := NewGRPCPermission("test.TestService", "Read")
+	write := NewGRPCPermission("test.TestService", "Write")
+	a, b := New(), New()
+	for _, r := range []RBAC{a, b, a} {
+		if err := Grant(r, "reader", read); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Grant(a, "reader", write); err != nil {
+		t.Fatal(err)
+	}
+	if !a.IsGranted("reader", read, nil) || !a.IsGranted("reader", write, nil) {
+		t.Error("expected a reader to be granted read and write")
+	}
+	if !b.IsGranted("reader", read, nil) {
+		t.Error("expected b reader to be granted read")
+	}
+	if b.IsGranted("reader", write, nil) {
+		t.Error("expected b reader not to be granted write")
+	}
+}
+
+func TestInherit(t *testing.T) {
+	read := NewGRPCPermission("test.TestService", "Read")
+	r := New()
+	for i := 0; i < 2; i++ {
+		if err := Inherit(r, "admin", "writer", "reader"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Grant(r, "reader", read); err != nil {
+		t.Fatal(err)
+	}
+	parents, err := r.GetParents("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parents) != 2 {
+		t.Errorf("expected 2 parents, got %v", parents)
+	}
+	if !r.IsGranted("admin", read, nil) {
+		t.Error("expected admin to inherit reader permissions")
+	}
+}
+
+func TestJoinErrors(t *testing.T) {
+	a, b := errors.New("a"), errors.New("b")
+	tests := []struct {
+		name string
+		errs []error
+		want string
+	}{
+		{name: "none"},
+		{name: "nil", errs: []error{nil, nil}},
+		{name: "one", errs: []error{nil, a}, want: "a"},
+		{name: "many", errs: []error{a, nil, b}, want: "a; b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := JoinErrors(tt.errs...)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("expected nil error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -16,9 +16,9 @@ package grpc_rbac
 
 import (
 	"context"
-	"errors"
 
 	"github.com/mikespook/gorbac/v2"
+	"google.golang.org/grpc/metadata"
 )
 
 type (
@@ -30,8 +30,69 @@ func NewStdRole(name string) *StdRole {
 	return gorbac.NewStdRole(name)
 }
 
+// RoleInfo is the role human readable information, as defined by the rbac.Role options
+type RoleInfo struct {
+	ID          string
+	DisplayName string
+	Description string
+	Deprecated  bool
+	Labels      map[string]string
+}
+
+// DescribedRole is a StdRole exposing its information
+type DescribedRole struct {
+	*StdRole
+	info RoleInfo
+}
+
+// NewDescribedRole returns a new role with the info ID
+func NewDescribedRole(info RoleInfo) *DescribedRole {
+	return &DescribedRole{StdRole: NewStdRole(info.ID), info: info}
+}
+
+// Info returns the role information
+func (r *DescribedRole) Info() RoleInfo {
+	return r.info
+}
+
+// DisplayName returns the role human readable name, or its id if it is not defined
+func (r *DescribedRole) DisplayName() string {
+	if r.info.DisplayName == "" {
+		return r.info.ID
+	}
+	return r.info.DisplayName
+}
+
+// Description returns the role description
+func (r *DescribedRole) Description() string {
+	return r.info.Description
+}
+
+// Deprecated reports whether the role is deprecated
+func (r *DescribedRole) Deprecated() bool {
+	return r.info.Deprecated
+}
+
+// Labels returns the role labels
+func (r *DescribedRole) Labels() map[string]string {
+	return r.info.Labels
+}
+
 type RoleFunc func(ctx context.Context) ([]Role, error)
 
 func UnimplementedRoleFunc(ctx context.Context) ([]gorbac.Role, error) {
-	return nil, errors.New("grpc rbac: missing role function")
+	return nil, ErrMissingRoleFunc
+}
+
+// OutgoingMetadataRoleFunc returns a RoleFunc reading the roles ids from the outgoing metadata key,
+// to be used with WithClientRoleFunc
+func OutgoingMetadataRoleFunc(key string) RoleFunc {
+	return func(ctx context.Context) ([]Role, error) {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		var roles []Role
+		for _, v := range md.Get(key) {
+			roles = append(roles, NewStdRole(v))
+		}
+		return roles, nil
+	}
 }
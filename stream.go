@@ -0,0 +1,157 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// StreamFilter authorizes the messages sent on a server stream.
+// It returns the message to send, which may be a redacted copy of m, and false if the message must be dropped.
+// The message must not be modified in place as it may be shared with the service.
+type StreamFilter func(ctx context.Context, fullMethod string, roles []Role, m interface{}) (interface{}, bool)
+
+type filter struct {
+	// dropped is first to be 64-bit aligned for atomic operations
+	dropped uint64
+	fn      StreamFilter
+}
+
+func (r *rbac) RegisterStreamFilter(fullMethod string, fn StreamFilter) {
+	r.filters.Store(fullMethod, &filter{fn: fn})
+}
+
+func (r *rbac) DroppedMessages(fullMethod string) uint64 {
+	v, ok := r.filters.Load(fullMethod)
+	if !ok {
+		return 0
+	}
+	return atomic.LoadUint64(&v.(*filter).dropped)
+}
+
+type wrapper struct {
+	grpc.ServerStream
+	// base is the stream context holding the engine, the roles are resolved with
+	base   context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	rbac   *rbac
+	method string
+	mu     sync.RWMutex
+	roles  []Role
+	err    error
+}
+
+func (w *wrapper) Context() context.Context {
+	return w.ctx
+}
+
+func (w *wrapper) SendMsg(m interface{}) error {
+	roles, err := w.authorize()
+	if err != nil {
+		return err
+	}
+	if v, ok := w.rbac.filters.Load(w.method); ok {
+		f := v.(*filter)
+		if m, ok = f.fn(w.ctx, w.method, roles, m); !ok {
+			atomic.AddUint64(&f.dropped, 1)
+			return nil
+		}
+	}
+	return w.ServerStream.SendMsg(w.rbac.mask(roles, m))
+}
+
+func (w *wrapper) RecvMsg(m interface{}) error {
+	roles, err := w.authorize()
+	if err != nil {
+		return err
+	}
+	if err := w.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return w.rbac.check(roles, m)
+}
+
+// authorize returns the caller roles, re-checking the authorization first
+// if the stream must be re-authorized on every message
+func (w *wrapper) authorize() ([]Role, error) {
+	if w.rbac.reauth && w.rbac.reauthInterval == 0 {
+		if err := w.reauthorize(); err != nil {
+			return nil, err
+		}
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.roles, w.err
+}
+
+// watch re-checks the authorization on policy changes and on every reauthInterval if set,
+// until the stream is done or the access is lost
+func (w *wrapper) watch() {
+	var tick <-chan time.Time
+	if w.rbac.reauthInterval > 0 {
+		t := time.NewTicker(w.rbac.reauthInterval)
+		defer t.Stop()
+		tick = t.C
+	}
+	changes := w.rbac.changes()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-changes:
+		case <-tick:
+		}
+		changes = w.rbac.changes()
+		if err := w.reauthorize(); err != nil {
+			return
+		}
+	}
+}
+
+// reauthorize resolves the caller roles and matches them against the stream method again.
+// When the access is lost, the error is kept to be returned by the stream and the stream context is canceled.
+func (w *wrapper) reauthorize() error {
+	roles, err := w.rbac.rematch(w.base, w.method)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	if err != nil {
+		w.err = err
+		w.cancel()
+		return err
+	}
+	w.roles = roles
+	return nil
+}
+
+func (w *wrapper) callerRoles() []Role {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.roles
+}
+
+func (w *wrapper) error() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.err
+}
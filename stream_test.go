@@ -0,0 +1,213 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+)
+
+// switchRoles is a RoleFunc returning roles which can be changed during the test
+type switchRoles struct {
+	mu    sync.Mutex
+	roles []string
+}
+
+func (s *switchRoles) set(roles ...string) {
+	s.mu.Lock()
+	s.roles = roles
+	s.mu.Unlock()
+}
+
+func (s *switchRoles) roleFunc(context.Context) ([]Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Role
+	for _, v := range s.roles {
+		out = append(out, NewStdRole(v))
+	}
+	return out, nil
+}
+
+// holdWatch starts a Watch stream held open by the server and waits for its first message
+func holdWatch(t *testing.T, c testpb.TestServiceClient) testpb.TestService_WatchClient {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	s, err := c.Watch(ctx, &testpb.Request{Resource: &testpb.Resource{Id: "hold", Children: []*testpb.Resource{{Id: "1"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestStreamReauthorization(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		// revoke removes the access once the stream is started
+		revoke func(r RBAC, roles *switchRoles)
+		code   codes.Code
+	}{
+		{
+			name: "roles change notified",
+			revoke: func(r RBAC, roles *switchRoles) {
+				roles.set("writer")
+				r.NotifyPolicyChange()
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "permission revoked",
+			revoke: func(r RBAC, roles *switchRoles) {
+				if err := r.Remove("reader"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name:     "roles change on interval",
+			interval: 10 * time.Millisecond,
+			revoke: func(r RBAC, roles *switchRoles) {
+				roles.set()
+			},
+			code: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles := &switchRoles{roles: []string{"reader"}}
+			r := newTestRBAC(t, WithRoleFunc(roles.roleFunc), WithStreamReauthorization(tt.interval))
+			s := holdWatch(t, serve(t, r))
+			tt.revoke(r, roles)
+			if _, err := s.Recv(); status.Code(err) != tt.code {
+				t.Errorf("got %v, want %v", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestStreamReauthorizationOnMessages(t *testing.T) {
+	roles := &switchRoles{roles: []string{"writer"}}
+	c := serve(t, newTestRBAC(t, WithRoleFunc(roles.roleFunc), WithStreamReauthorization(0)))
+	s, err := c.Upload(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(&testpb.Request{}); err != nil {
+		t.Fatal(err)
+	}
+	roles.set("reader")
+	if err := s.Send(&testpb.Request{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CloseAndRecv(); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("got %v, want PermissionDenied", err)
+	}
+}
+
+func TestStreamWithoutReauthorization(t *testing.T) {
+	roles := &switchRoles{roles: []string{"reader"}}
+	r := newTestRBAC(t, WithRoleFunc(roles.roleFunc))
+	c := serve(t, r)
+	got, err := watch(context.Background(), c, &testpb.Request{Resource: &testpb.Resource{Children: []*testpb.Resource{{}, {}}}})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("got %d messages, %v", len(got), err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	s, err := c.Watch(ctx, &testpb.Request{Resource: &testpb.Resource{Id: "hold", Children: []*testpb.Resource{{}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	roles.set()
+	r.NotifyPolicyChange()
+	if _, err := s.Recv(); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("got %v, want the stream to be kept until its deadline", err)
+	}
+}
+
+func TestStreamFilter(t *testing.T) {
+	r := newTestRBAC(t)
+	r.RegisterStreamFilter(watchMethod, func(ctx context.Context, fullMethod string, roles []Role, m interface{}) (interface{}, bool) {
+		if fullMethod != watchMethod {
+			t.Errorf("got method %s, want %s", fullMethod, watchMethod)
+		}
+		res := m.(*testpb.Response)
+		if res.GetResource().GetId() == "private" && !hasRole(roles, "admin") {
+			return nil, false
+		}
+		return &testpb.Response{Resource: &testpb.Resource{Id: res.GetResource().GetId() + "-filtered", Owner: res.GetResource().GetOwner()}}, true
+	})
+	c := serve(t, r)
+	req := &testpb.Request{Resource: &testpb.Resource{Children: []*testpb.Resource{{Id: "public", Owner: "owner"}, {Id: "private"}, {Id: "public"}}}}
+	tests := []struct {
+		role    string
+		ids     []string
+		owner   string
+		dropped uint64
+	}{
+		{role: "reader", ids: []string{"public-filtered", "public-filtered"}, dropped: 1},
+		{role: "admin", ids: []string{"public-filtered", "private-filtered", "public-filtered"}, owner: "owner", dropped: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			got, err := watch(withRoles(context.Background(), tt.role), c, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, v := range got {
+				ids = append(ids, v.GetId())
+			}
+			if !reflect.DeepEqual(ids, tt.ids) {
+				t.Errorf("got %v, want %v", ids, tt.ids)
+			}
+			if got[0].GetOwner() != tt.owner {
+				t.Errorf("the filtered messages should be masked, got owner %q", got[0].GetOwner())
+			}
+			if n := r.DroppedMessages(watchMethod); n != tt.dropped {
+				t.Errorf("got %d dropped messages, want %d", n, tt.dropped)
+			}
+		})
+	}
+	if n := r.DroppedMessages(readMethod); n != 0 {
+		t.Errorf("got %d dropped messages for a method without filter", n)
+	}
+}
+
+func hasRole(roles []Role, id string) bool {
+	for _, v := range roles {
+		if v.ID() == id {
+			return true
+		}
+	}
+	return false
+}
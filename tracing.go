@@ -0,0 +1,74 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"time"
+)
+
+// Tracer traces the authorization of the calls, see WithTracer.
+// The implementations must be safe for concurrent use.
+type Tracer interface {
+	// Authorize is called when the authorization of the method starts, including the streams re-authorizations,
+	// see Decision.Reauthorization. The returned context is used for the roles resolution,
+	// and the returned function is called with the decision.
+	Authorize(ctx context.Context, method string) (context.Context, func(d Decision))
+	// ResolveRoles is called before the RoleFunc call. The returned context is passed to the RoleFunc,
+	// and the returned function is called with its result.
+	ResolveRoles(ctx context.Context) (context.Context, func(roles []Role, err error))
+}
+
+// authorize starts the authorization of the method, or its re-authorization if reauth is true,
+// the returned function records the decision, err being the not mapped decision error
+func (r *rbac) authorize(ctx context.Context, method string, reauth bool) (context.Context, func(roles []Role, matched Role, err error)) {
+	start := time.Now()
+	var end func(Decision)
+	if r.tracer != nil {
+		ctx, end = r.tracer.Authorize(ctx, method)
+	}
+	return ctx, func(roles []Role, matched Role, err error) {
+		if r.metrics == nil && end == nil {
+			return
+		}
+		d := Decision{Method: method, Outcome: outcome(err), Roles: ids(roles), Duration: time.Since(start), Err: err, Reauthorization: reauth}
+		if matched != nil {
+			d.Role = matched.ID()
+		}
+		if r.metrics != nil {
+			r.metrics.Decision(ctx, d)
+		}
+		if end != nil {
+			end(d)
+		}
+	}
+}
+
+// resolve calls the RoleFunc, recording its duration and tracing it
+func (r *rbac) resolve(ctx context.Context, fn RoleFunc) ([]Role, error) {
+	var end func([]Role, error)
+	if r.tracer != nil {
+		ctx, end = r.tracer.ResolveRoles(ctx)
+	}
+	start := time.Now()
+	roles, err := fn(ctx)
+	if r.metrics != nil {
+		r.metrics.RoleResolution(ctx, time.Since(start), err)
+	}
+	if end != nil {
+		end(roles, err)
+	}
+	return roles, err
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_rbac
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go.linka.cloud/grpc-rbac/internal/testpb"
+)
+
+type traceKey struct{}
+
+// testTracer records the traced decisions and roles resolutions, and marks the traced contexts with traceKey
+type testTracer struct {
+	mu        sync.Mutex
+	methods   []string
+	decisions []Decision
+	resolved  [][]Role
+}
+
+func (t *testTracer) Authorize(ctx context.Context, method string) (context.Context, func(d Decision)) {
+	t.mu.Lock()
+	t.methods = append(t.methods, method)
+	t.mu.Unlock()
+	return context.WithValue(ctx, traceKey{}, method), func(d Decision) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.decisions = append(t.decisions, d)
+	}
+}
+
+func (t *testTracer) ResolveRoles(ctx context.Context) (context.Context, func(roles []Role, err error)) {
+	return ctx, func(roles []Role, err error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.resolved = append(t.resolved, roles)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	tr := &testTracer{}
+	var traced []interface{}
+	c := serve(t, newTestRBAC(t, WithTracer(tr), WithRoleFunc(func(ctx context.Context) ([]Role, error) {
+		traced = append(traced, ctx.Value(traceKey{}))
+		return incomingRoles(ctx)
+	})))
+	if _, err := c.Read(withRoles(context.Background(), "reader"), &testpb.Request{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Write(withRoles(context.Background(), "reader"), &testpb.Request{}); err == nil {
+		t.Fatal("expected permission denied")
+	}
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if len(tr.methods) != 2 || tr.methods[0] != readMethod || tr.methods[1] != writeMethod {
+		t.Errorf("unexpected traced methods %v", tr.methods)
+	}
+	if len(traced) != 2 || traced[0] != readMethod || traced[1] != writeMethod {
+		t.Errorf("the role func was not called with the traced context: %v", traced)
+	}
+	if len(tr.resolved) != 2 || len(tr.resolved[0]) != 1 || tr.resolved[0][0].ID() != "reader" {
+		t.Errorf("unexpected traced roles resolutions %v", tr.resolved)
+	}
+	if len(tr.decisions) != 2 {
+		t.Fatalf("expected 2 decisions, got %d", len(tr.decisions))
+	}
+	if d := tr.decisions[0]; d.Outcome != OutcomeAllowed || d.Role != "reader" || d.Err != nil {
+		t.Errorf("unexpected allowed decision %+v", d)
+	}
+	if d := tr.decisions[1]; d.Outcome != OutcomeDenied || d.Role != "" || d.Err == nil {
+		t.Errorf("unexpected denied decision %+v", d)
+	}
+}